@@ -16,14 +16,19 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-func New(c host.Config, o Options) *Builder {
+// New 创建构建器,节点发现失败时返回错误
+func New(c host.Config, o Options) (*Builder, error) {
 	msp, err := mspId.New(o.Mode)
 	if err != nil {
-		log.Fatalln("mspid:", err)
+		return nil, fmt.Errorf("mspid:%w", err)
 	}
 	h, err := host.New(context.TODO(), o.Mode, &c)
 	if err != nil {
-		log.Fatalln("host:", err)
+		return nil, fmt.Errorf("host:%w", err)
+	}
+	// 节点发现时从容器环境变量中获取到的mspid优先
+	if f, ok := h.(mspId.FetchMspId); ok {
+		msp = mspId.NewChain(f, msp)
 	}
 
 	b := &Builder{
@@ -37,7 +42,7 @@ func New(c host.Config, o Options) *Builder {
 		Orderers:               make(map[string]Payload, 1),
 		Peers:                  make(map[string]Payload, 2),
 	}
-	return b
+	return b, nil
 }
 
 func (b *Builder) Build(cc *parse.CryptoConfig) error {
@@ -45,6 +50,27 @@ func (b *Builder) Build(cc *parse.CryptoConfig) error {
 		return fmt.Errorf("valid: %w", err)
 	}
 
+	// configtx.yaml中声明的mspid优先
+	tx, err := b.configTx()
+	if err != nil {
+		return fmt.Errorf("configTx:%w", err)
+	}
+	if tx != nil {
+		b.mspId = mspId.NewChain(mspId.NewMap(tx.MspIds()), b.mspId)
+	}
+
+	if err := b.validUsers(cc); err != nil {
+		return fmt.Errorf("validUsers:%w", err)
+	}
+
+	if err := b.validTLS(); err != nil {
+		return fmt.Errorf("validTLS:%w", err)
+	}
+
+	if err := b.validGrpc(); err != nil {
+		return fmt.Errorf("validGrpc:%w", err)
+	}
+
 	// client
 	if err := b.client(cc); err != nil {
 		return fmt.Errorf("client:%w", err)
@@ -70,18 +96,18 @@ func (b *Builder) Build(cc *parse.CryptoConfig) error {
 		return fmt.Errorf("peers:%w", err)
 	}
 
-	// EntityMatchers
-	if err := b.entityMatchers(cc); err != nil {
-		return fmt.Errorf("entityMatchers:%w", err)
-	}
-
-	// CertificateAuthorities
+	// CertificateAuthorities 先于EntityMatchers生成,CA的地址映射只包含已生成的CA
 	if b.opts.CA {
 		if err := b.certificateAuthorities(cc); err != nil {
 			return fmt.Errorf("certificateAuthorities:%w", err)
 		}
 	}
 
+	// EntityMatchers
+	if err := b.entityMatchers(cc); err != nil {
+		return fmt.Errorf("entityMatchers:%w", err)
+	}
+
 	// Operations
 	if b.opts.CA {
 		if err := b.operations(cc); err != nil {
@@ -96,46 +122,73 @@ func (b *Builder) Build(cc *parse.CryptoConfig) error {
 		}
 	}
 
+	// 严格模式下不允许配置中遗留占位符
+	if b.opts.Strict && len(b.unresolved) > 0 {
+		return &UnresolvedError{Endpoints: b.Unresolved()}
+	}
 	return nil
 }
 
-// Write .
-func (b *Builder) Write(d []byte) (int, error) {
-	return 0, nil
+// JSON 生成json,先序列化为yaml再转换,保证key以及time.Duration等取值与yaml完全一致
+func (b *Builder) JSON() ([]byte, error) {
+	v, err := b.generic()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(v, "", "  ")
 }
 
-// Output .
-func (b *Builder) Output(dir string) error {
-	return nil
+// YAML 生成yaml
+func (b *Builder) YAML() ([]byte, error) {
+	node, err := b.node()
+	if err != nil {
+		return nil, err
+	}
+	return encodeYAML(node)
 }
 
-// JSON 生成json
-func (b *Builder) JSON() ([]byte, error) {
-	return json.MarshalIndent(b, "  ", " ")
+// node 生成yaml节点,开启--comments时增加注释
+func (b *Builder) node() (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(b); err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	if b.opts.Comments {
+		b.comment(&node)
+	}
+	return &node, nil
 }
 
-// YAML 生成yaml
-func (b *Builder) YAML() ([]byte, error) {
+func encodeYAML(node *yaml.Node) ([]byte, error) {
 	buf := bytes.NewBuffer(nil)
 	enc := yaml.NewEncoder(buf)
 	defer enc.Close()
 	enc.SetIndent(2)
-	if err := enc.Encode(b); err != nil {
+	if err := enc.Encode(node); err != nil {
 		return nil, fmt.Errorf("encode: %w", err)
 	}
 	return buf.Bytes(), nil
 }
 
-// Content 根据配置类型生成相应格式内容
+// Content 根据配置类型生成相应格式内容,指定了模板时使用模板渲染
 func (b *Builder) Content() ([]byte, error) {
 	var (
 		content []byte
 		err     error
 	)
+	if b.opts.Template != "" {
+		return b.Template()
+	}
 
 	switch b.opts.FileType {
 	case "json":
 		content, err = b.JSON()
+	case "env":
+		content, err = b.Env()
+	case "properties":
+		content, err = b.Properties()
+	case "toml":
+		content, err = b.TOML()
 	case "yaml":
 		fallthrough
 	default:
@@ -146,36 +199,28 @@ func (b *Builder) Content() ([]byte, error) {
 
 // client
 func (b *Builder) client(cc *parse.CryptoConfig) error {
+	level, err := b.logLevel()
+	if err != nil {
+		return err
+	}
+	security, err := b.security()
+	if err != nil {
+		return err
+	}
 	var client = Client{
-		Organization: b.opts.OrgName,
-		Logging:      Logging{Level: "info"},
-		CryptoConfig: Path{Path: ""},
-		CredentialStore: CredentialStore{
-			Path: "./data/keystore", // todo:考虑可配置
-			CryptoStore: Path{
-				Path: "./data/msp", // todo:考虑可配置
-			},
-		},
-		Bccsp: Bccsp{
-			Security: Security{
-				Enabled: true,
-				Default: struct {
-					Provider string `json:"provider,omitempty" yaml:"provider"`
-				}{Provider: "SW"},
-				HashAlgorithm: "SHA2",
-				SoftVerify:    true,
-				Level:         256,
-			},
-		},
-	}
-
-	// 如果输入的组织名不存在则随机取一个组织名称
-	if _, ok := cc.Orgs[parse.OrgName(b.opts.OrgName)]; !ok {
-		for name := range cc.Orgs {
-			client.Organization = string(name)
-			break
-		}
+		Organization:    b.opts.OrgName,
+		Logging:         Logging{Level: level},
+		CryptoConfig:    Path{Path: ""},
+		CredentialStore: b.credentialStore(),
+		Bccsp:           Bccsp{Security: security},
+	}
+
+	name, err := b.clientOrg(cc)
+	if err != nil {
+		return err
 	}
+	client.Organization = string(name)
+	b.note("来源: --org "+b.opts.OrgName, "client", "organization")
 
 	if b.opts.Pem {
 		// todo:
@@ -186,48 +231,48 @@ func (b *Builder) client(cc *parse.CryptoConfig) error {
 
 	// 开启双tls
 	if b.opts.DoubleTls {
-		var (
-			clientKeyPem  PemPath
-			clientCertPem PemPath
-			err           error
-			scp           = true
-		)
-		// 模糊查询对应的组织并且找到当前组织Admin的证书
-		for name, org := range cc.Orgs {
-			if strings.Contains(string(name), b.opts.OrgName) {
-				for domain, user := range org.Users {
-					if domain.UserName() == b.opts.User {
-						clientKeyPem, err = newPemPath(b.opts.Pem, user.TLS.Key)
-						if err != nil {
-							return fmt.Errorf("newPemPath:%w", err)
-						}
-						clientCertPem, err = newPemPath(b.opts.Pem, user.TLS.Cert)
-						if err != nil {
-							return fmt.Errorf("newPemPath:%w", err)
-						}
-					}
-				}
-			}
+		kc, err := b.clientCert(cc, name)
+		if err != nil {
+			return err
 		}
-
 		// windows go1.17版本不支持证书池
-		if runtime.GOARCH == "Windows" {
-			scp = false
-		}
-
 		client.Bccsp.TLSCerts = TLSCerts{
-			SystemCertPool: scp,
-			Client: KC{ // 根据组织选择证书
-				Key:  Key{PemPath: clientKeyPem},
-				Cert: Cert{PemPath: clientCertPem},
-			},
+			SystemCertPool: runtime.GOOS != "windows",
+			Client:         kc,
 		}
 	}
+	if err := b.validClientTLS(cc); err != nil {
+		return err
+	}
 
 	b.Client = client
 	return nil
 }
 
+// clientOrg 根据--org查找客户端所属的peer组织,支持组织域名、组织简称以及mspid,
+// 未找到或者匹配到多个组织时返回错误
+func (b *Builder) clientOrg(cc *parse.CryptoConfig) (parse.OrgName, error) {
+	return b.findOrg(cc, b.opts.OrgName)
+}
+
+// findOrg 按组织域名、组织名或者mspid精确查找peer组织
+func (b *Builder) findOrg(cc *parse.CryptoConfig, org string) (parse.OrgName, error) {
+	var list []string
+	for _, name := range cc.GetOrgName() {
+		if b.matchOrg(parse.OrgName(name), org) {
+			list = append(list, name)
+		}
+	}
+	switch len(list) {
+	case 1:
+		return parse.OrgName(list[0]), nil
+	case 0:
+		return "", fmt.Errorf("organization %q not found, expect one of %s", org, strings.Join(cc.GetOrgName(), "|"))
+	default:
+		return "", fmt.Errorf("organization %q is ambiguous: %s", org, strings.Join(list, "|"))
+	}
+}
+
 // organizations
 // #
 // # list of participating organizations in this network
@@ -279,155 +324,151 @@ func (b *Builder) client(cc *parse.CryptoConfig) error {
 //
 //	  # Needed to load users crypto keys and certs for this org (absolute path or relative to global crypto path, DEV mode)
 //	  cryptoPath: ordererOrganizations/example.com/users/{username}@example.com/msp
+//
+// 每个排序组织单独生成一个组织配置,key为排序组织域名,与peer组织重名时增加-orderer后缀
 func (b *Builder) organizations(cc *parse.CryptoConfig) error {
 	for name, org := range cc.Orgs {
-		var (
-			peers   = make([]string, 0, len(org.Users))
-			keyPem  PemPath
-			certPem PemPath
-			oao     OrgAndOrder
-			err     error
-			o       = string(name)
-			// o       = name.Org()
-		)
-
-		if o == "" {
-			o = string(name)
-			log.Printf("[organizations] warn org name not match:%s\n", name)
-		}
-
+		o := string(name)
 		if _, ok := b.Organizations[o]; ok {
 			continue
 		}
-
-		for domain := range org.Server {
-			peers = append(peers, string(domain))
+		oao, err := b.organization(name, org, "peerOrganizations")
+		if err != nil {
+			return fmt.Errorf("organization %s:%w", name, err)
 		}
-		oao.Peers = peers
+		b.Organizations[o] = oao
+		b.note(b.mspIdFrom(o), "organizations", o, "mspid")
+		b.own(o, org)
+	}
 
-		mi, ok := b.mspId.GetMspId(string(name))
-		if !ok {
-			mi = "{待替换}"
-			log.Printf("[organizations] mspid not found: %s\n", name)
+	for name, order := range cc.Order {
+		o := string(name)
+		if _, ok := cc.Orgs[name]; ok {
+			o = string(name) + "-orderer"
+			log.Printf("[organizations] order org name conflicts with peer org: %s, use %s\n", name, o)
 		}
-		oao.MspId = mi
-
-		// TODO: 待实现
-		if b.opts.CA {
-			oao.CertificateAuthorities = []string{}
+		oao, err := b.organization(name, order, "ordererOrganizations")
+		if err != nil {
+			return fmt.Errorf("organization %s:%w", name, err)
 		}
+		b.Organizations[o] = oao
+		b.note(b.mspIdFrom(string(name)), "organizations", o, "mspid")
+		b.own(o, order)
+	}
 
-		for domain, user := range org.Users {
-			if domain.UserName() == b.opts.User {
-				if b.opts.Pem {
-					// TODO:
-					// 1. 待确认`{username}`是否是代表程序自动的去找用户,还是说需要我们自己替换用户
-					// 2. cryptoPath 支持绝对路径需要考虑
-					// peerOrganizations/org1.example.com/users/{username}@org1.example.com/msp
-					oao.CryptoPath = fmt.Sprintf("peerOrganizations/%s/users/%s/msp", name, domain)
-					continue
-				}
-
-				keyPem, err = newPemPath(b.opts.Pem, user.Msp.KeyStore.Key)
-				if err != nil {
-					return fmt.Errorf("newPemPath:%w", err)
-				}
-				certPem, err = newPemPath(b.opts.Pem, user.Msp.SignCerts.Cert)
-				if err != nil {
-					return fmt.Errorf("newPemPath:%w", err)
-				}
-
-				oao.Users = map[string]KC{
-					b.opts.User: {
-						Key:  Key{PemPath: keyPem},
-						Cert: Cert{PemPath: certPem},
-					},
-				}
-			}
-		}
+	return nil
+}
 
-		b.Organizations[o] = oao
+// own 记录节点所属的组织
+func (b *Builder) own(key string, org *parse.Org) {
+	if b.owner == nil {
+		b.owner = make(map[string]string)
 	}
+	for domain := range org.Server {
+		b.owner[string(domain)] = key
+	}
+}
 
-	// TODO:ordererorg 查看官方示例中配置中带排序节点配置此处待研究,以下内容需要待验证暂时先放这里
-	for name, order := range cc.Order {
-		var (
-			keyPem  PemPath
-			certPem PemPath
-			oao     OrgAndOrder
-			err     error
-			o       = name.Org()
-		)
-
-		if o == "" {
-			o = string(name)
-			log.Printf("[organizations] order org name not match: %s\n", name)
+// organization 生成单个组织配置,dir为crypto-config中组织所在目录 peerOrganizations ordererOrganizations
+func (b *Builder) organization(name parse.OrgName, org *parse.Org, dir string) (OrgAndOrder, error) {
+	var oao OrgAndOrder
+
+	// 排序组织的节点不属于organizations.peers
+	if dir == "peerOrganizations" {
+		oao.Peers = org.Servers()
+	}
+
+	mi, ok := b.mspId.GetMspId(string(name))
+	if !ok {
+		mi = placeholderMspId
+		log.Printf("[organizations] mspid not found: %s\n", name)
+	}
+	oao.MspId = mi
+
+	// TODO: 待实现
+	if b.opts.CA && dir == "peerOrganizations" {
+		oao.CertificateAuthorities = []string{}
+	}
+
+	users, err := b.selectUsers(name, org)
+	if err != nil {
+		return OrgAndOrder{}, fmt.Errorf("selectUsers:%w", err)
+	}
+	if b.opts.Pem {
+		// TODO: cryptoPath 支持绝对路径需要考虑
+		// 单个用户时使用具体路径,多个用户时由sdk根据用户名替换{username}
+		// peerOrganizations/org1.example.com/users/{username}@org1.example.com/msp
+		switch len(users) {
+		case 0:
+		case 1:
+			oao.CryptoPath = fmt.Sprintf("%s/%s/users/%s/msp", dir, name, users[0])
+		default:
+			oao.CryptoPath = fmt.Sprintf("%s/%s/users/{username}@%s/msp", dir, name, name)
 		}
+		return oao, nil
+	}
 
-		if _, ok := b.Organizations[o]; ok {
-			continue
+	for _, domain := range users {
+		user := org.Users[domain]
+		keyPem, err := newPemPath(b.opts.Pem, user.Msp.KeyStore.Key)
+		if err != nil {
+			return OrgAndOrder{}, fmt.Errorf("newPemPath:%w", err)
+		}
+		certPem, err := newPemPath(b.opts.Pem, user.Msp.SignCerts.Cert)
+		if err != nil {
+			return OrgAndOrder{}, fmt.Errorf("newPemPath:%w", err)
 		}
 
-		mi, ok := b.mspId.GetMspId(string(name))
-		if !ok {
-			mi = "{待替换}"
-			log.Printf("[organizations] mspid not found: %s\n", name)
+		if oao.Users == nil {
+			oao.Users = make(map[string]KC, len(users))
 		}
-		oao.MspId = mi
-
-		for domain, user := range order.Users {
-			if domain.UserName() == b.opts.User {
-				if b.opts.Pem {
-					oao.CryptoPath = fmt.Sprintf("peerOrganizations/%s/users/%s/msp", name, domain)
-					continue
-				}
-
-				keyPem, err = newPemPath(b.opts.Pem, user.Msp.KeyStore.Key)
-				if err != nil {
-					return fmt.Errorf("newPemPath:%w", err)
-				}
-				certPem, err = newPemPath(b.opts.Pem, user.Msp.SignCerts.Cert)
-				if err != nil {
-					return fmt.Errorf("newPemPath:%w", err)
-				}
-
-				oao.Users = map[string]KC{
-					b.opts.User: {
-						Key:  Key{PemPath: keyPem},
-						Cert: Cert{PemPath: certPem},
-					},
-				}
-			}
+		oao.Users[domain.UserName()] = KC{
+			Key:  Key{PemPath: keyPem},
+			Cert: Cert{PemPath: certPem},
 		}
-		// todo:默认使用第一条数据当有多个order？
-		b.Organizations["ordererorg"] = oao
-		break
 	}
-
-	return nil
+	return oao, nil
 }
 
-// channel
+// channel 每个通道只包含成员组织的peer节点,配置了排序组织时同时生成通道的排序节点,节点角色见peerPolicies
 func (b *Builder) channel(cc *parse.CryptoConfig) error {
-	var peer = make(map[string]PeerPolicy, 4)
-	for _, org := range cc.Orgs {
-		for domain := range org.Server {
-			// note: 此处用的是map序列化,由于golang的特性map无序,在实际链接中也不影响
-			if _, ok := peer[string(domain)]; !ok {
-				peer[string(domain)] = PeerPolicy{
-					EndorsingPeer:  true,
-					ChaincodeQuery: true,
-					LedgerQuery:    true,
-					EventSource:    true,
-				}
+	names, members, err := b.members()
+	if err != nil {
+		return fmt.Errorf("members:%w", err)
+	}
+	policies, err := b.peerPolicies(cc)
+	if err != nil {
+		return fmt.Errorf("peerPolicies:%w", err)
+	}
+	policy, err := b.policy()
+	if err != nil {
+		return fmt.Errorf("policy:%w", err)
+	}
+
+	for _, name := range names {
+		var peers, orderers []string
+		if ch, ok := members[name]; ok {
+			if peers, orderers, err = b.resolveChannel(cc, name, ch); err != nil {
+				return err
 			}
+		} else {
+			for _, org := range cc.Orgs {
+				peers = append(peers, org.Servers()...)
+			}
+			b.note("未配置通道成员,包含所有peer节点", "channels", name)
 		}
-	}
 
-	b.Channels[b.opts.ChannelName] = ChannelPeer{
-		Peer:   peer,
-		Policy: Policy{}, // todo: 考虑补充
-		// Order:  nil, // note: golang中早期配置中可以配置order通道但是后期v1.0.0sdk配置会提示废弃因此此处不生成
+		// note: json以及yaml序列化map时按key排序,输出顺序固定
+		var peer = make(map[string]PeerPolicy, len(peers))
+		for _, domain := range peers {
+			peer[domain] = policies[domain]
+		}
+
+		b.Channels[name] = ChannelPeer{
+			Peer:     peer,
+			Orderers: orderers,
+			Policy:   policy,
+		}
 	}
 	return nil
 }
@@ -445,25 +486,13 @@ func (b *Builder) order(cc *parse.CryptoConfig) error {
 				return fmt.Errorf("newPemPath:%s", err)
 			}
 
-			var port = "${PORT}"
-			if h, ok := b.host.GetHost(string(domain)); !ok {
-				log.Printf("[order] not found port: %s", domain)
-			} else {
-				port = h.Port()
-			}
-
-			b.Orderers[string(domain)] = Payload{
-				Url: fmt.Sprintf("%s:%s", domain, port),
-				GrpcOptions: GrpcOptions{
-					SSLTargetNameOverride: string(domain),
-					KeepAliveTime:         0,
-					KeepAliveTimeout:      0,
-					KeepAlivePermit:       0,
-					FailFast:              false,
-					AllowInsecure:         false,
-				},
-				TlsCACerts: tlsCaCerts,
+			p := Payload{
+				Url:         b.endpoint("orderers", string(domain)),
+				GrpcOptions: b.grpcOptions("orderers", "orderer", string(domain)),
+				TlsCACerts:  TLSCACerts{PemPath: tlsCaCerts},
 			}
+			b.applyTLS("orderers", string(domain), &p)
+			b.Orderers[string(domain)] = p
 		}
 	}
 	return nil
@@ -482,95 +511,35 @@ func (b *Builder) peers(cc *parse.CryptoConfig) error {
 				return fmt.Errorf("newPemPath:%w", err)
 			}
 
-			var port = "${PORT}"
-			if h, ok := b.host.GetHost(string(domain)); !ok {
-				log.Printf("[order] not found port:%s", domain)
-			} else {
-				port = h.Port()
-			}
-
-			b.Peers[string(domain)] = Payload{
-				Url: fmt.Sprintf("%s:%s", domain, port),
-				GrpcOptions: GrpcOptions{
-					SSLTargetNameOverride: string(domain),
-					KeepAliveTime:         0,
-					KeepAliveTimeout:      0,
-					KeepAlivePermit:       0,
-					FailFast:              false,
-					AllowInsecure:         false,
-				},
-				TlsCACerts: tlsCaCerts,
+			p := Payload{
+				Url:         b.endpoint("peers", string(domain)),
+				GrpcOptions: b.grpcOptions("peers", "peer", string(domain)),
+				TlsCACerts:  TLSCACerts{PemPath: tlsCaCerts},
 			}
+			b.applyTLS("peers", string(domain), &p)
+			b.Peers[string(domain)] = p
 		}
 	}
 	return nil
 }
 
-// entityMatchers
-func (b *Builder) entityMatchers(cc *parse.CryptoConfig) error {
-	var (
-		peer  = make([]Matcher, 0, len(cc.Orgs)*2)
-		order = make([]Matcher, 0, len(cc.Order)*2)
-		ca    = make([]Matcher, 0, 2)
-	)
-
-	for _, org := range cc.Orgs {
-		for domain := range org.Server {
-			url, ok := b.host.GetHost(string(domain))
-			if !ok {
-				log.Printf("[entityMatchers] not found host: %s\n", domain)
-				url = host.Host(domain)
-			}
-			peer = append(peer, Matcher{
-				Pattern:                             fmt.Sprintf("(\\w*)%s(\\w*)", string(domain)), // todo:考虑正则规则
-				UrlSubstitutionExp:                  fmt.Sprintf("grpcs://%s:%s", url.IP(), url.Port()),
-				SSLTargetOverrideUrlSubstitutionExp: string(domain),
-				MappedHost:                          string(domain),
-				MappedName:                          "", // todo:
-				IgnoreEndpoint:                      false,
-			})
-		}
+// endpoint 获取服务地址,未解析到端口时使用占位符并记录
+func (b *Builder) endpoint(section, domain string) string {
+	var port = host.PlaceholderPort
+	if h, ok := b.host.GetHost(domain); !ok {
+		log.Printf("[%s] not found port: %s", section, domain)
+	} else {
+		port = h.Port()
 	}
 
-	for _, o := range cc.Order {
-		for domain := range o.Server {
-			url, ok := b.host.GetHost(string(domain))
-			if !ok {
-				log.Printf("[entityMatchers] not found host: %s\n", domain)
-				url = host.Host(domain)
-			}
-			order = append(order, Matcher{
-				Pattern:                             fmt.Sprintf("(\\w*)%s(\\w*)", string(domain)), // todo:考虑正则规则
-				UrlSubstitutionExp:                  fmt.Sprintf("grpcs://%s:%s", url.IP(), url.Port()),
-				SSLTargetOverrideUrlSubstitutionExp: string(domain),
-				MappedHost:                          string(domain),
-				MappedName:                          "", // todo:
-				IgnoreEndpoint:                      false,
-			})
-		}
+	url := fmt.Sprintf("%s:%s", domain, port)
+	if hasPlaceholder(url) {
+		b.unresolve(section, domain, "url", url)
 	}
-
-	// todo:CertificateAuthority
-	// for _, order := range cc.Order {
-	//	for domain := range order.Server {
-	//		peer = append(peer, Matcher{
-	//			Pattern:                             fmt.Sprintf("(\\w*)%s(\\w*)", string(domain)), // todo:考虑正则规则
-	//			UrlSubstitutionExp:                  "grpcs://{ip}:{port}",
-	//			SSLTargetOverrideUrlSubstitutionExp: string(domain),
-	//			MappedHost:                          string(domain),
-	//			MappedName:                          "", // todo:
-	//			IgnoreEndpoint:                      false,
-	//		})
-	//	}
-	// }
-
-	b.EntityMatchers.Peer = peer
-	b.EntityMatchers.Orderer = order
-	b.EntityMatchers.CertificateAuthority = ca
-	return nil
+	return url
 }
 
-// certificateAuthorities
+// certificateAuthorities TODO: 待实现,未生成CA配置时同样不生成CA的地址映射
 func (b *Builder) certificateAuthorities(cc *parse.CryptoConfig) error {
 
 	return nil
@@ -0,0 +1,237 @@
+package builder
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/fgc/parse"
+	"github.com/chaunsin/fgc/parse/host"
+	"github.com/chaunsin/fgc/parse/mspId"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestClientOrg(t *testing.T) {
+	cc := &parse.CryptoConfig{Orgs: map[parse.OrgName]*parse.Org{
+		"org1.example.com": {},
+		"org2.example.com": {},
+		"org2.test.com":    {},
+	}}
+	b := &Builder{mspId: mspId.NewMap(map[string]string{"org1.example.com": "Org1MSP"})}
+
+	for raw, want := range map[string]parse.OrgName{
+		"org1":             "org1.example.com",
+		"Org1MSP":          "org1.example.com",
+		"org2.example.com": "org2.example.com",
+	} {
+		b.opts.OrgName = raw
+		got, err := b.clientOrg(cc)
+		if err != nil || got != want {
+			t.Fatalf("clientOrg(%q): got %s %v want %s", raw, got, err, want)
+		}
+	}
+
+	for _, raw := range []string{"", "org3", "org2"} {
+		b.opts.OrgName = raw
+		if _, err := b.clientOrg(cc); err == nil {
+			t.Fatalf("clientOrg(%q): expected error", raw)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	b := &Builder{
+		Version: "v1.0.0",
+		Client:  Client{Organization: "org1.example.com"},
+		Organizations: map[string]OrgAndOrder{
+			"org1.example.com": {MspId: "Org1MSP", Peers: []string{"peer0.org1.example.com"}},
+		},
+		Channels: map[string]ChannelPeer{
+			"mychannel": {
+				Peer:   map[string]PeerPolicy{"peer0.org1.example.com": {EndorsingPeer: true}},
+				Policy: policyPresets[defaultPolicy],
+			},
+		},
+		Peers: map[string]Payload{
+			"peer0.org1.example.com": {
+				Url:         "peer0.org1.example.com:7051",
+				GrpcOptions: GrpcOptions{SSLTargetNameOverride: "peer0.org1.example.com", KeepAliveTime: 10 * time.Second},
+			},
+		},
+		CertificateAuthorities: map[string]CertificateAuthorities{
+			"ca.org1.example.com": {
+				Url:       "https://ca.org1.example.com:7054",
+				Registrar: Registrar{EnrollId: "admin", EnrollSecret: "adminpw"},
+				CaName:    "ca-org1",
+			},
+		},
+	}
+
+	y, err := b.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := b.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 与sdk(viper)加载方式一致,分别解析为通用结构后比较
+	var fromYaml, fromJson interface{}
+	if err := yaml.Unmarshal(y, &fromYaml); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(j, &fromJson); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(fromYaml)
+	_ = json.Unmarshal(data, &fromYaml)
+	if !reflect.DeepEqual(fromYaml, fromJson) {
+		t.Fatalf("json not equivalent to yaml:\n%s\n%s", y, j)
+	}
+
+	// json可以重新加载为Builder
+	var loaded Builder
+	if err := yaml.Unmarshal(j, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Peers, b.Peers) || !reflect.DeepEqual(loaded.Channels, b.Channels) ||
+		!reflect.DeepEqual(loaded.CertificateAuthorities, b.CertificateAuthorities) {
+		t.Fatalf("round trip mismatch:\n%+v\n%+v", loaded.Peers, b.Peers)
+	}
+	// fabric-sdk-go读取registrar.enrollSecret
+	for _, data := range [][]byte{y, j} {
+		if !strings.Contains(string(data), "enrollSecret") || strings.Contains(string(data), "enroll_secret") {
+			t.Fatalf("registrar enrollSecret key:\n%s", data)
+		}
+	}
+}
+
+// testHost 节点发现结果,未包含的域名视为未解析
+type testHost map[string]host.Host
+
+func (h testHost) GetHost(domain string) (host.Host, bool) {
+	v, ok := h[domain]
+	return v, ok
+}
+
+func (h testHost) Close() error { return nil }
+
+// testOrg 内存中的组织,包含节点以及Admin、User1用户
+func testOrg(dir, name string, servers ...string) *parse.Org {
+	var (
+		root = "crypto-config/" + dir + "/" + name
+		org  = &parse.Org{
+			TLSCA:  parse.Package{Cert: parse.File(root + "/tlsca/tlsca." + name + "-cert.pem")},
+			Server: make(map[parse.OrgDomain]*parse.Serve),
+			Users:  make(map[parse.UserDomain]*parse.User),
+		}
+	)
+	for _, s := range servers {
+		org.Server[parse.OrgDomain(s)] = &parse.Serve{}
+	}
+	for _, u := range []string{"Admin", "User1"} {
+		path := root + "/users/" + u + "@" + name + "/tls/"
+		org.Users[parse.UserDomain(u+"@"+name)] = &parse.User{
+			Name: u,
+			TLS:  parse.Package{CA: parse.File(path + "ca.crt"), Cert: parse.File(path + "client.crt"), Key: parse.File(path + "client.key")},
+		}
+	}
+	return org
+}
+
+// testCrypto 两个peer组织以及两个排序组织
+func testCrypto() *parse.CryptoConfig {
+	return &parse.CryptoConfig{
+		Orgs: map[parse.OrgName]*parse.Org{
+			"org1.example.com": testOrg("peerOrganizations", "org1.example.com", "peer0.org1.example.com", "peer1.org1.example.com"),
+			"org2.example.com": testOrg("peerOrganizations", "org2.example.com", "peer0.org2.example.com"),
+		},
+		Order: map[parse.OrgName]*parse.Org{
+			"example.com": testOrg("ordererOrganizations", "example.com", "orderer.example.com"),
+			"ord2.com":    testOrg("ordererOrganizations", "ord2.com", "orderer0.ord2.com"),
+		},
+	}
+}
+
+// testBuilder 与New一致,使用内存中的节点发现结果以及mspid,证书仅输出路径
+func testBuilder(o Options, h host.FetchHost) *Builder {
+	o.Pem = true
+	if o.OrgName == "" {
+		o.OrgName = "org1"
+	}
+	return &Builder{
+		opts: o,
+		mspId: mspId.NewMap(map[string]string{
+			"org1.example.com": "Org1MSP",
+			"org2.example.com": "Org2MSP",
+			"example.com":      "OrdererMSP",
+			"ord2.com":         "Ord2MSP",
+		}),
+		host:                   h,
+		Version:                "v1.0.0",
+		Channels:               make(map[string]ChannelPeer),
+		Organizations:          make(map[string]OrgAndOrder),
+		CertificateAuthorities: make(map[string]CertificateAuthorities),
+		Orderers:               make(map[string]Payload),
+		Peers:                  make(map[string]Payload),
+	}
+}
+
+func TestOrderOrganizations(t *testing.T) {
+	cc := testCrypto()
+	b := testBuilder(Options{Users: []string{"Admin"}}, partialHost)
+	if err := b.Build(cc); err != nil {
+		t.Fatal(err)
+	}
+
+	// 每个排序组织单独生成组织配置,不包含peers
+	for key, want := range map[string]OrgAndOrder{
+		"example.com": {MspId: "OrdererMSP", CryptoPath: "ordererOrganizations/example.com/users/Admin@example.com/msp"},
+		"ord2.com":    {MspId: "Ord2MSP", CryptoPath: "ordererOrganizations/ord2.com/users/Admin@ord2.com/msp"},
+		"org1.example.com": {
+			MspId:      "Org1MSP",
+			CryptoPath: "peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp",
+			Peers:      []string{"peer0.org1.example.com", "peer1.org1.example.com"},
+		},
+	} {
+		if got := b.Organizations[key]; !reflect.DeepEqual(got, want) {
+			t.Fatalf("organizations %s:\ngot  %+v\nwant %+v", key, got, want)
+		}
+	}
+	if len(b.Organizations) != 4 {
+		t.Fatalf("organizations: %d", len(b.Organizations))
+	}
+	for _, domain := range []string{"orderer.example.com", "orderer0.ord2.com"} {
+		if _, ok := b.Orderers[domain]; !ok {
+			t.Fatalf("orderers: %s not found", domain)
+		}
+	}
+
+	// 多个用户时由sdk替换{username}
+	b = testBuilder(Options{Users: []string{"all"}}, partialHost)
+	if err := b.Build(cc); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Organizations["ord2.com"].CryptoPath; got != "ordererOrganizations/ord2.com/users/{username}@ord2.com/msp" {
+		t.Fatalf("ord2.com cryptoPath: %s", got)
+	}
+
+	// 排序组织与peer组织重名时增加-orderer后缀
+	cc = testCrypto()
+	cc.Order["org1.example.com"] = testOrg("ordererOrganizations", "org1.example.com", "orderer.org1.example.com")
+	b = testBuilder(Options{Users: []string{"Admin"}}, partialHost)
+	if err := b.Build(cc); err != nil {
+		t.Fatal(err)
+	}
+	want := OrgAndOrder{MspId: "Org1MSP", CryptoPath: "ordererOrganizations/org1.example.com/users/Admin@org1.example.com/msp"}
+	if got := b.Organizations["org1.example.com-orderer"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("organizations org1.example.com-orderer: %+v", got)
+	}
+	if got := b.Organizations["org1.example.com"].Peers; len(got) != 2 {
+		t.Fatalf("organizations org1.example.com peers: %v", got)
+	}
+}
@@ -0,0 +1,185 @@
+package builder
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// bundleCryptoDir 打包目录中证书所在目录,同时作为client.cryptoconfig.path
+const bundleCryptoDir = "crypto-config"
+
+// Bundle 生成可以直接分发的目录,只复制配置中实际引用到的证书以及私钥,
+// 证书路径改写为相对配置文件所在目录的路径,需要在配置文件所在目录下运行或者挂载到容器的工作目录中
+//
+//	dir
+//	├── config.yaml
+//	└── crypto-config
+//	    └── peerOrganizations/org1.example.com/...
+func (b *Builder) Bundle(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("MkdirAll:%w", err)
+	}
+	if b.opts.Pem {
+		if err := b.bundleCrypto(dir); err != nil {
+			return fmt.Errorf("bundleCrypto:%w", err)
+		}
+	}
+	content, err := b.Content()
+	if err != nil {
+		return fmt.Errorf("Content:%w", err)
+	}
+	if err := WriteFile(filepath.Join(dir, "config."+b.Ext()), content, b.ConfigMode()); err != nil {
+		return fmt.Errorf("WriteFile:%w", err)
+	}
+	return nil
+}
+
+// bundleCrypto 复制引用到的证书文件以及cryptoPath下用户的signcerts、keystore目录
+func (b *Builder) bundleCrypto(dir string) error {
+	// 输出目录下的crypto-config为证书源目录时,复制后会包含所有证书
+	root, err := b.cryptoRoot()
+	if err != nil {
+		return err
+	}
+	same, err := samePath(root, filepath.Join(dir, bundleCryptoDir))
+	if err != nil {
+		return fmt.Errorf("Abs:%w", err)
+	}
+	if same {
+		return fmt.Errorf("output %s contains the source crypto-config %s, use a dedicated --output", dir, root)
+	}
+	files, err := b.cryptoFiles(func(rel string) string {
+		return "./" + path.Join(bundleCryptoDir, rel)
+	})
+	if err != nil {
+		return err
+	}
+	for _, rel := range sortedKeys(files) {
+		if err := copyFile(files[rel], filepath.Join(dir, bundleCryptoDir, filepath.FromSlash(rel))); err != nil {
+			return fmt.Errorf("copyFile:%w", err)
+		}
+	}
+	b.Client.CryptoConfig.Path = "./" + bundleCryptoDir
+	return nil
+}
+
+// samePath 两个路径是否指向同一个目录
+func samePath(a, b string) (bool, error) {
+	a, err := filepath.Abs(a)
+	if err != nil {
+		return false, err
+	}
+	b, err = filepath.Abs(b)
+	if err != nil {
+		return false, err
+	}
+	return a == b, nil
+}
+
+// cryptoFiles 返回配置中实际引用到的文件 相对crypto-config的路径(/分隔) => 源文件路径,
+// 包括证书、私钥以及cryptoPath下用户的signcerts、keystore,证书路径使用relocate改写
+func (b *Builder) cryptoFiles(relocate func(rel string) string) (map[string]string, error) {
+	root, err := b.cryptoRoot()
+	if err != nil {
+		return nil, err
+	}
+	var files = make(map[string]string)
+	_ = b.eachPath(func(p *PemPath) error {
+		_, rel, _ := splitCrypto(p.Path)
+		rel = filepath.ToSlash(rel)
+		files[rel] = p.Path
+		p.Path = relocate(rel)
+		return nil
+	})
+
+	// 多个用户时cryptoPath中包含{username},包含组织下所有匹配的用户
+	for _, name := range sortedKeys(b.Organizations) {
+		cp := b.Organizations[name].CryptoPath
+		if cp == "" {
+			continue
+		}
+		msps, err := filepath.Glob(filepath.Join(root, strings.ReplaceAll(cp, "{username}", "*")))
+		if err != nil {
+			return nil, fmt.Errorf("Glob:%w", err)
+		}
+		if len(msps) == 0 {
+			return nil, fmt.Errorf("%s not found in %s", cp, root)
+		}
+		for _, msp := range msps {
+			for _, sub := range []string{"signcerts", "keystore"} {
+				list, err := filepath.Glob(filepath.Join(msp, sub, "*"))
+				if err != nil {
+					return nil, fmt.Errorf("Glob:%w", err)
+				}
+				for _, f := range list {
+					rel, err := filepath.Rel(root, f)
+					if err != nil {
+						return nil, err
+					}
+					files[filepath.ToSlash(rel)] = f
+				}
+			}
+		}
+	}
+	return files, nil
+}
+
+// Tarball 将Bundle生成的config.<ext>以及crypto-config打包为tar.gz写入w,保留文件权限,路径相对于dir,
+// dir中的其他文件不打包
+func (b *Builder) Tarball(dir string, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	var entries = []string{filepath.Join(dir, "config."+b.Ext())}
+	if b.opts.Pem {
+		entries = append(entries, filepath.Join(dir, bundleCryptoDir))
+	}
+	for _, entry := range entries {
+		if err := tarTree(tw, dir, entry); err != nil {
+			return fmt.Errorf("tar:%w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("tar:%w", err)
+	}
+	return gw.Close()
+}
+
+// tarTree 将root目录或者文件写入tw,路径相对于dir
+func tarTree(tw *tar.Writer, dir, root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
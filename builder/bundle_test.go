@@ -0,0 +1,102 @@
+package builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "crypto-config")
+	org := filepath.Join(src, "peerOrganizations", "org1.example.com")
+	for _, name := range []string{
+		"tlsca/tlsca.org1.example.com-cert.pem",
+		"users/Admin@org1.example.com/msp/keystore/priv_sk",
+		"users/Admin@org1.example.com/msp/signcerts/Admin@org1.example.com-cert.pem",
+		"users/Admin@org1.example.com/msp/cacerts/ca.pem",
+		"users/User1@org1.example.com/msp/keystore/priv_sk",
+		"users/User1@org1.example.com/msp/signcerts/User1@org1.example.com-cert.pem",
+		"peers/peer0.org1.example.com/tls/server.key",
+	} {
+		path := filepath.Join(org, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := &Builder{
+		opts: Options{Pem: true},
+		Organizations: map[string]OrgAndOrder{
+			"org1.example.com": {MspId: "Org1MSP", CryptoPath: "peerOrganizations/org1.example.com/users/{username}@org1.example.com/msp"},
+		},
+		Peers: map[string]Payload{
+			"peer0.org1.example.com": {Url: "peer0.org1.example.com:7051", TlsCACerts: TLSCACerts{PemPath: PemPath{Path: filepath.Join(org, "tlsca/tlsca.org1.example.com-cert.pem")}}},
+		},
+	}
+	// 输出到证书源目录所在目录
+	if err := b.Bundle(filepath.Dir(src)); err == nil {
+		t.Error("bundle into the source crypto-config: expect error")
+	}
+	dir := t.TempDir()
+	if err := b.Bundle(dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Peers["peer0.org1.example.com"].TlsCACerts.Path; got != "./crypto-config/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem" {
+		t.Errorf("tlsCACerts path %s", got)
+	}
+
+	// 输出目录中的其他文件不打包
+	if err := os.WriteFile(filepath.Join(dir, "old.tar.gz"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := b.Tarball(dir, &buf); err != nil {
+		t.Fatal(err)
+	}
+	gr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		tr    = tar.NewReader(gr)
+		files []string
+	)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		files = append(files, hdr.Name)
+		if strings.HasSuffix(hdr.Name, "priv_sk") && hdr.Mode&0077 != 0 {
+			t.Errorf("%s mode %o", hdr.Name, hdr.Mode)
+		}
+	}
+	sort.Strings(files)
+	// 只包含引用到的证书,cacerts以及节点证书不复制
+	want := []string{
+		"config.yaml",
+		"crypto-config/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem",
+		"crypto-config/peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp/keystore/priv_sk",
+		"crypto-config/peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp/signcerts/Admin@org1.example.com-cert.pem",
+		"crypto-config/peerOrganizations/org1.example.com/users/User1@org1.example.com/msp/keystore/priv_sk",
+		"crypto-config/peerOrganizations/org1.example.com/users/User1@org1.example.com/msp/signcerts/User1@org1.example.com-cert.pem",
+	}
+	if strings.Join(files, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q\nwant %q", files, want)
+	}
+}
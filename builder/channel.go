@@ -0,0 +1,227 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/chaunsin/fgc/parse"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultChannel = "mychannel"
+
+// Channel 通道成员,组织可以是组织域名、组织简称(org1)或者mspid,排序可以是排序组织或者排序节点域名
+type Channel struct {
+	Orgs     []string `json:"orgs,omitempty" yaml:"orgs,omitempty"`
+	Orderers []string `json:"orderers,omitempty" yaml:"orderers,omitempty"`
+}
+
+// parseChannelOrgs 解析 mychannel=org1.example.com,org2.example.com 格式的通道成员
+func parseChannelOrgs(raw string) (string, Channel, error) {
+	kv := strings.SplitN(raw, "=", 2)
+	if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+		return "", Channel{}, fmt.Errorf("invalid channel orgs %q, expect name=org1,org2", raw)
+	}
+	var ch Channel
+	for _, o := range strings.Split(kv[1], ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			ch.Orgs = append(ch.Orgs, o)
+		}
+	}
+	return strings.TrimSpace(kv[0]), ch, nil
+}
+
+// loadChannels 读取通道成员配置文件,key为通道名称
+func loadChannels(path string) (map[string]Channel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile:%w", err)
+	}
+	var resp map[string]Channel
+	if err := yaml.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("Unmarshal:%w", err)
+	}
+	return resp, nil
+}
+
+// fromProfile 根据configtx.yaml中的profile生成通道成员
+func fromProfile(p parse.ConfigTxProfile) Channel {
+	var ch Channel
+	// 优先使用MSPDir中的组织名称,其次使用mspid
+	name := func(o parse.ConfigTxOrg) string {
+		if n := o.OrgName(); n != "" {
+			return n
+		}
+		return o.ID
+	}
+	if p.Application != nil {
+		for _, o := range p.Application.Organizations {
+			ch.Orgs = append(ch.Orgs, name(o))
+		}
+	}
+	if p.Orderer != nil {
+		for _, c := range p.Orderer.EtcdRaft.Consenters {
+			ch.Orderers = append(ch.Orderers, c.Host)
+		}
+		if len(ch.Orderers) == 0 {
+			for _, o := range p.Orderer.Organizations {
+				ch.Orderers = append(ch.Orderers, name(o))
+			}
+		}
+	}
+	return ch
+}
+
+// configTx 读取--configtx指定的configtx.yaml,未指定时返回nil
+func (b *Builder) configTx() (*parse.ConfigTx, error) {
+	if b.opts.ConfigTx == "" || b.tx != nil {
+		return b.tx, nil
+	}
+	tx, err := parse.ReadConfigTx(b.opts.ConfigTx)
+	if err != nil {
+		return nil, fmt.Errorf("ReadConfigTx:%w", err)
+	}
+	b.tx = tx
+	return tx, nil
+}
+
+// members 汇总通道成员,优先级 --channel-orgs > 通道配置文件 > configtx.yaml,
+// 返回排序后的通道名称,没有配置成员的通道包含所有peer节点
+func (b *Builder) members() ([]string, map[string]Channel, error) {
+	var (
+		members  = make(map[string]Channel)
+		profiles = make(map[string]string) // 通道名称 => profile
+		names    []string
+	)
+	for _, raw := range b.opts.Channels {
+		name := raw
+		if kv := strings.SplitN(raw, ":", 2); len(kv) == 2 {
+			name = kv[0]
+			profiles[name] = kv[1]
+		}
+		names = append(names, name)
+	}
+
+	tx, err := b.configTx()
+	if err != nil {
+		return nil, nil, err
+	}
+	if tx != nil {
+		for _, name := range names {
+			profile, ok := profiles[name]
+			if !ok {
+				profile = name
+			}
+			p, ok := tx.Profiles[profile]
+			if !ok || p.Application == nil {
+				if _, explicit := profiles[name]; explicit {
+					return nil, nil, fmt.Errorf("profile %s not found in %s", profile, b.opts.ConfigTx)
+				}
+				continue
+			}
+			members[name] = fromProfile(p)
+			b.note("来源: configtx.yaml profile "+profile, "channels", name)
+		}
+	}
+
+	if b.opts.ChannelFile != "" {
+		list, err := loadChannels(b.opts.ChannelFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loadChannels:%w", err)
+		}
+		for name, ch := range list {
+			members[name] = ch
+			b.note("来源: "+b.opts.ChannelFile, "channels", name)
+		}
+	}
+
+	for _, raw := range b.opts.ChannelOrgs {
+		name, ch, err := parseChannelOrgs(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		members[name] = ch
+		b.note("来源: --channel-orgs", "channels", name)
+	}
+
+	// 只在成员配置中声明的通道同样需要生成
+	for name := range members {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		names = append(names, defaultChannel)
+	}
+	sort.Strings(names)
+	var uniq = names[:0]
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			uniq = append(uniq, name)
+		}
+	}
+	return uniq, members, nil
+}
+
+// matchOrg 判断名称是否指向该组织,支持组织域名、组织简称(org1)以及mspid
+func (b *Builder) matchOrg(org parse.OrgName, name string) bool {
+	if name == "" {
+		return false
+	}
+	if string(org) == name || strings.SplitN(string(org), ".", 2)[0] == name {
+		return true
+	}
+	id, ok := b.mspId.GetMspId(string(org))
+	return ok && id == name
+}
+
+// resolveChannel 解析通道成员对应的peer节点以及排序节点
+func (b *Builder) resolveChannel(cc *parse.CryptoConfig, name string, ch Channel) (peers []string, orderers []string, err error) {
+	var seen = make(map[string]bool)
+	add := func(list *[]string, domain string) {
+		if !seen[domain] {
+			seen[domain] = true
+			*list = append(*list, domain)
+		}
+	}
+
+	for _, member := range append(append([]string{}, ch.Orgs...), ch.Orderers...) {
+		var found bool
+		for orgName, org := range cc.Orgs {
+			if b.matchOrg(orgName, member) {
+				found = true
+				for domain := range org.Server {
+					add(&peers, string(domain))
+				}
+				continue
+			}
+			if _, ok := org.Server[parse.OrgDomain(member)]; ok {
+				found = true
+				add(&peers, member)
+			}
+		}
+		for orgName, org := range cc.Order {
+			if b.matchOrg(orgName, member) {
+				found = true
+				for domain := range org.Server {
+					add(&orderers, string(domain))
+				}
+				continue
+			}
+			if _, ok := org.Server[parse.OrgDomain(member)]; ok {
+				found = true
+				add(&orderers, member)
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("channel %s: unknown organization or node %q", name, member)
+		}
+	}
+	if len(peers) == 0 {
+		return nil, nil, fmt.Errorf("channel %s has no peer", name)
+	}
+	sort.Strings(peers)
+	sort.Strings(orderers)
+	return peers, orderers, nil
+}
@@ -0,0 +1,204 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testConfigTx = `
+Organizations:
+  - &OrdererOrg
+    Name: OrdererOrg
+    ID: OrdererMSP
+    MSPDir: crypto-config/ordererOrganizations/example.com/msp
+  - &Org1
+    Name: Org1MSP
+    ID: Org1MSP
+    MSPDir: crypto-config/peerOrganizations/org1.example.com/msp
+  - &Org2
+    Name: Org2MSP
+    ID: Org2MSP
+    MSPDir: crypto-config/peerOrganizations/org2.example.com/msp
+Profiles:
+  TwoOrgsChannel:
+    Orderer:
+      Organizations:
+        - *OrdererOrg
+      EtcdRaft:
+        Consenters:
+          - Host: orderer.example.com
+            Port: 7050
+    Application:
+      Organizations:
+        - *Org1
+        - *Org2
+  Org2Channel:
+    Orderer:
+      Organizations:
+        - *OrdererOrg
+    Application:
+      Organizations:
+        - *Org2
+`
+
+const testChannelFile = `
+filechannel:
+  orgs: [org2.example.com]
+  orderers: [ord2.com]
+mychannel:
+  orgs: [org2]
+`
+
+func TestChannelMembers(t *testing.T) {
+	var (
+		dir         = t.TempDir()
+		configTx    = filepath.Join(dir, "configtx.yaml")
+		channelFile = filepath.Join(dir, "channels.yaml")
+		org1        = []string{"peer0.org1.example.com", "peer1.org1.example.com"}
+		org2        = []string{"peer0.org2.example.com"}
+		all         = []string{"peer0.org1.example.com", "peer0.org2.example.com", "peer1.org1.example.com"}
+	)
+	if err := os.WriteFile(configTx, []byte(testConfigTx), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(channelFile, []byte(testChannelFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type member struct {
+		peers    []string
+		orderers []string
+	}
+	for _, c := range []struct {
+		name string
+		opts Options
+		want map[string]member
+		err  string
+	}{
+		{
+			name: "default",
+			want: map[string]member{"mychannel": {peers: all}},
+		},
+		{
+			name: "profile",
+			opts: Options{ConfigTx: configTx, Channels: []string{"mychannel:TwoOrgsChannel", "Org2Channel"}},
+			want: map[string]member{
+				"mychannel":   {peers: all, orderers: []string{"orderer.example.com"}},
+				"Org2Channel": {peers: org2, orderers: []string{"orderer.example.com"}},
+			},
+		},
+		{
+			name: "channel without profile",
+			opts: Options{ConfigTx: configTx, Channels: []string{"other"}},
+			want: map[string]member{"other": {peers: all}},
+		},
+		{
+			name: "unknown profile",
+			opts: Options{ConfigTx: configTx, Channels: []string{"mychannel:Unknown"}},
+			err:  "profile Unknown not found",
+		},
+		{
+			name: "profile without application",
+			opts: Options{ConfigTx: configTx, Channels: []string{"mychannel:OrdererGenesis"}},
+			err:  "profile OrdererGenesis not found",
+		},
+		{
+			name: "channel orgs",
+			opts: Options{Channels: []string{"mychannel"}, ChannelOrgs: []string{"mychannel=org1, ord2.com", "ch2=Org2MSP,orderer.example.com"}},
+			want: map[string]member{
+				"mychannel": {peers: org1, orderers: []string{"orderer0.ord2.com"}},
+				"ch2":       {peers: org2, orderers: []string{"orderer.example.com"}},
+			},
+		},
+		{
+			name: "channel orgs by node",
+			opts: Options{ChannelOrgs: []string{"mychannel=peer1.org1.example.com,org2.example.com"}},
+			want: map[string]member{"mychannel": {peers: []string{"peer0.org2.example.com", "peer1.org1.example.com"}}},
+		},
+		{
+			name: "channel config",
+			opts: Options{ConfigTx: configTx, Channels: []string{"mychannel:TwoOrgsChannel"}, ChannelFile: channelFile},
+			want: map[string]member{
+				"mychannel":   {peers: org2},
+				"filechannel": {peers: org2, orderers: []string{"orderer0.ord2.com"}},
+			},
+		},
+		{
+			name: "channel orgs override channel config",
+			opts: Options{ChannelFile: channelFile, ChannelOrgs: []string{"mychannel=org1"}},
+			want: map[string]member{
+				"mychannel":   {peers: org1},
+				"filechannel": {peers: org2, orderers: []string{"orderer0.ord2.com"}},
+			},
+		},
+		{
+			name: "unknown org",
+			opts: Options{ChannelOrgs: []string{"mychannel=org3"}},
+			err:  `unknown organization or node "org3"`,
+		},
+		{
+			name: "no peer",
+			opts: Options{ChannelOrgs: []string{"mychannel=example.com"}},
+			err:  "channel mychannel has no peer",
+		},
+		{
+			name: "invalid channel orgs",
+			opts: Options{ChannelOrgs: []string{"org1,org2"}},
+			err:  "invalid channel orgs",
+		},
+		{
+			name: "missing channel config",
+			opts: Options{ChannelFile: filepath.Join(dir, "none.yaml")},
+			err:  "loadChannels",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			b := testBuilder(c.opts, partialHost)
+			err := b.Build(testCrypto())
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("Build: got %v want %q", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+
+			var got = make(map[string]member, len(b.Channels))
+			for name, ch := range b.Channels {
+				var m = member{orderers: ch.Orderers}
+				for domain := range ch.Peer {
+					m.peers = append(m.peers, domain)
+				}
+				sort.Strings(m.peers)
+				got[name] = m
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("channels:\ngot  %+v\nwant %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestConfigTxMspId(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "configtx.yaml")
+	if err := os.WriteFile(path, []byte(strings.Replace(testConfigTx, "ID: Org2MSP", "ID: Org2TxMSP", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b := testBuilder(Options{ConfigTx: path}, partialHost)
+	if err := b.Build(testCrypto()); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	// configtx.yaml中声明的mspid优先
+	if got := b.Organizations["org2.example.com"].MspId; got != "Org2TxMSP" {
+		t.Fatalf("org2 mspid: %s", got)
+	}
+	if got := b.Organizations["org1.example.com"].MspId; got != "Org1MSP" {
+		t.Fatalf("org1 mspid: %s", got)
+	}
+}
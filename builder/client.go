@@ -0,0 +1,177 @@
+package builder
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/chaunsin/fgc/parse"
+)
+
+const (
+	defaultLogLevel        = "info"
+	defaultCredentialStore = "./data/keystore"
+	defaultCryptoStore     = "./data/msp"
+	defaultProvider        = "SW"
+	defaultHashAlgorithm   = "SHA2"
+	defaultSecurityLevel   = 256
+)
+
+// logLevel sdk日志级别,大小写不敏感
+func (b *Builder) logLevel() (string, error) {
+	level := strings.ToLower(b.opts.LogLevel)
+	switch level {
+	case "":
+		return defaultLogLevel, nil
+	case "debug", "info", "warning", "error", "critical":
+		return level, nil
+	case "warn":
+		return "warning", nil
+	}
+	return "", fmt.Errorf("invalid log level %q, expect debug|info|warning|error|critical", b.opts.LogLevel)
+}
+
+// credentialStore 用户证书存储目录,未指定时使用./data/keystore以及./data/msp
+func (b *Builder) credentialStore() CredentialStore {
+	store := CredentialStore{Path: b.opts.StorePath, CryptoStore: Path{Path: b.opts.CryptoStorePath}}
+	if store.Path == "" {
+		store.Path = defaultCredentialStore
+	}
+	if store.CryptoStore.Path == "" {
+		store.CryptoStore.Path = defaultCryptoStore
+	}
+	return store
+}
+
+// security 加密服务配置,provider为PKCS11时需要指定动态库以及token label
+func (b *Builder) security() (Security, error) {
+	s := Security{
+		Enabled:       true,
+		HashAlgorithm: strings.ToUpper(b.opts.HashAlgorithm),
+		SoftVerify:    true,
+		Level:         int64(b.opts.SecurityLevel),
+	}
+	s.Default.Provider = strings.ToUpper(b.opts.Provider)
+	if s.Default.Provider == "" {
+		s.Default.Provider = defaultProvider
+	}
+	if s.HashAlgorithm == "" {
+		s.HashAlgorithm = defaultHashAlgorithm
+	}
+	if s.Level == 0 {
+		s.Level = defaultSecurityLevel
+	}
+
+	switch s.HashAlgorithm {
+	case "SHA2", "SHA3":
+	default:
+		return Security{}, fmt.Errorf("invalid hash algorithm %q, expect SHA2|SHA3", b.opts.HashAlgorithm)
+	}
+	switch s.Level {
+	case 256, 384:
+	default:
+		return Security{}, fmt.Errorf("invalid security level %d, expect 256|384", s.Level)
+	}
+
+	switch s.Default.Provider {
+	case "SW":
+	case "PKCS11":
+		s.Library, s.Pin, s.Label = b.opts.PKCS11Library, b.opts.PKCS11Pin, b.opts.PKCS11Label
+		if s.Library == "" || s.Label == "" {
+			return Security{}, fmt.Errorf("PKCS11 provider requires library and label")
+		}
+		// 未指定pin时写入占位符,运行sdk时通过环境变量覆盖,避免配置以ConfigMap等形式分发时泄露
+		if s.Pin == "" {
+			s.Pin = placeholderPin
+			b.note("运行sdk时通过环境变量FABRIC_SDK_CLIENT_BCCSP_SECURITY_PIN设置", "client", "BCCSP", "security", "pin")
+		} else {
+			b.note("来源: --pkcs11-pin,配置文件权限为0600", "client", "BCCSP", "security", "pin")
+		}
+	default:
+		return Security{}, fmt.Errorf("invalid BCCSP provider %q, expect SW|PKCS11", b.opts.Provider)
+	}
+	return s, nil
+}
+
+// clientTLS 节点单独使用的双向tls客户端证书 eg: peer0.org2.example.com=org2
+type clientTLS struct {
+	pattern string // 节点域名通配符或者组织域名
+	org     parse.OrgName
+	kc      KC
+}
+
+// clientCert 双向tls客户端证书,使用组织选择的第一个用户的tls证书,没有可用用户或者证书缺失时返回错误
+func (b *Builder) clientCert(cc *parse.CryptoConfig, name parse.OrgName) (KC, error) {
+	org, ok := cc.Orgs[name]
+	if !ok {
+		return KC{}, fmt.Errorf("client tls: organization %s not found", name)
+	}
+	users, err := b.selectUsers(name, org)
+	if err != nil {
+		return KC{}, fmt.Errorf("selectUsers:%w", err)
+	}
+	if len(users) == 0 {
+		return KC{}, fmt.Errorf("client tls: no user selected in %s, use --user %s=<user>", name, name)
+	}
+	user := org.Users[users[0]]
+	if user.TLS.Key == "" || user.TLS.Cert == "" {
+		return KC{}, fmt.Errorf("client tls: tls key or cert of %s not found", users[0])
+	}
+	key, err := newPemPath(b.opts.Pem, user.TLS.Key)
+	if err != nil {
+		return KC{}, fmt.Errorf("newPemPath:%w", err)
+	}
+	cert, err := newPemPath(b.opts.Pem, user.TLS.Cert)
+	if err != nil {
+		return KC{}, fmt.Errorf("newPemPath:%w", err)
+	}
+	return KC{Key: Key{PemPath: key}, Cert: Cert{PemPath: cert}}, nil
+}
+
+// validClientTLS 解析--peer-client-tls规则 pattern=org,需要开启--tls,
+// fabric-sdk-go以及node sdk的节点配置不支持单独的客户端证书,只能用于java模板或者自定义模板
+func (b *Builder) validClientTLS(cc *parse.CryptoConfig) error {
+	if len(b.opts.PeerClientTLS) == 0 {
+		return nil
+	}
+	if !b.opts.DoubleTls {
+		return fmt.Errorf("--peer-client-tls requires --tls")
+	}
+	if _, builtin := builtinTemplates[b.opts.Template]; b.opts.Template == "" || builtin && b.opts.Template != "java" {
+		return fmt.Errorf("--peer-client-tls is only supported by the java template or a custom --template, fabric-sdk-go and node sdk ignore per peer client certs")
+	}
+	b.clientTLS = make([]clientTLS, 0, len(b.opts.PeerClientTLS))
+	for _, raw := range b.opts.PeerClientTLS {
+		kv := strings.SplitN(raw, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return fmt.Errorf("invalid peer client tls %q, expect pattern=org", raw)
+		}
+		r := clientTLS{pattern: strings.TrimSpace(kv[0])}
+		if _, err := path.Match(r.pattern, ""); err != nil {
+			return fmt.Errorf("invalid peer client tls pattern %q: %w", r.pattern, err)
+		}
+		org, err := b.findOrg(cc, strings.TrimSpace(kv[1]))
+		if err != nil {
+			return fmt.Errorf("peer client tls %q: %w", raw, err)
+		}
+		if r.kc, err = b.clientCert(cc, org); err != nil {
+			return err
+		}
+		r.org = org
+		b.clientTLS = append(b.clientTLS, r)
+	}
+	return nil
+}
+
+// applyClientTLS 节点匹配--peer-client-tls规则时使用单独的客户端证书,后面的规则优先
+func (b *Builder) applyClientTLS(section, domain string, p *Payload) {
+	for i := len(b.clientTLS) - 1; i >= 0; i-- {
+		r := b.clientTLS[i]
+		if (tlsRule{pattern: r.pattern}).match(domain) {
+			kc := r.kc
+			p.TlsCACerts.Client = &kc
+			b.note("双向tls客户端证书,来源: --peer-client-tls "+r.pattern+"="+string(r.org), section, domain, "tlsCACerts", "client")
+			return
+		}
+	}
+}
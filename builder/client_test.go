@@ -0,0 +1,89 @@
+package builder
+
+import "testing"
+
+func TestSecurity(t *testing.T) {
+	b := &Builder{}
+	s, err := b.security()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Default.Provider != "SW" || s.HashAlgorithm != "SHA2" || s.Level != 256 || s.Library != "" {
+		t.Errorf("default: %+v", s)
+	}
+	if level, err := b.logLevel(); err != nil || level != "info" {
+		t.Errorf("log level: %s %v", level, err)
+	}
+
+	b.opts = Options{Provider: "pkcs11", HashAlgorithm: "sha3", SecurityLevel: 384, PKCS11Library: "/usr/lib/softhsm/libsofthsm2.so", PKCS11Label: "fabric"}
+	if s, err = b.security(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Default.Provider != "PKCS11" || s.HashAlgorithm != "SHA3" || s.Level != 384 || s.Label != "fabric" || s.Pin != placeholderPin {
+		t.Errorf("pkcs11: %+v", s)
+	}
+	if mode := (&Builder{opts: Options{Pem: true}}).ConfigMode(); mode != 0644 {
+		t.Errorf("pin placeholder mode %v, want 0644", mode)
+	}
+
+	// 指定pin时写入配置,配置文件权限为0600
+	b.opts.PKCS11Pin, b.opts.Pem = "98765432", true
+	if s, err = b.security(); err != nil || s.Pin != "98765432" {
+		t.Errorf("pkcs11 pin: %+v %v", s, err)
+	}
+	if mode := b.ConfigMode(); mode != 0600 {
+		t.Errorf("pin mode %v, want 0600", mode)
+	}
+
+	for _, opts := range []Options{
+		{Provider: "PKCS11", PKCS11Label: "fabric"},
+		{Provider: "HSM"},
+		{HashAlgorithm: "MD5"},
+		{SecurityLevel: 512},
+	} {
+		b.opts = opts
+		if _, err := b.security(); err == nil {
+			t.Errorf("%+v: expect error", opts)
+		}
+	}
+	b.opts = Options{LogLevel: "trace"}
+	if _, err := b.logLevel(); err == nil {
+		t.Error("log level trace: expect error")
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	b := &Builder{opts: Options{PeerClientTLS: []string{"*.org2.example.com=org2"}}}
+	if err := b.validClientTLS(nil); err == nil {
+		t.Error("--peer-client-tls without --tls: expect error")
+	}
+
+	// fabric-sdk-go以及node sdk不支持节点单独的客户端证书
+	cc := testCrypto()
+	for tmpl, ok := range map[string]bool{"": false, "go": false, "node": false, "java": true, "./custom.tmpl": true} {
+		o := Options{DoubleTls: true, Template: tmpl, Users: []string{"Admin"}, PeerClientTLS: []string{"*.org2.example.com=org2"}}
+		err := testBuilder(o, testHost{}).validClientTLS(cc)
+		if ok != (err == nil) {
+			t.Errorf("template %q: got %v", tmpl, err)
+		}
+	}
+
+	org2 := KC{Key: Key{PemPath: PemPath{Path: "org2/client.key"}}, Cert: Cert{PemPath: PemPath{Path: "org2/client.crt"}}}
+	peer0 := KC{Key: Key{PemPath: PemPath{Path: "peer0/client.key"}}, Cert: Cert{PemPath: PemPath{Path: "peer0/client.crt"}}}
+	b.clientTLS = []clientTLS{
+		{pattern: "org2.example.com", org: "org2.example.com", kc: org2},
+		{pattern: "peer0.org2.example.com", org: "org3.example.com", kc: peer0},
+	}
+	for domain, want := range map[string]*KC{
+		"peer0.org2.example.com": &peer0,
+		"peer1.org2.example.com": &org2,
+		"peer0.org1.example.com": nil,
+	} {
+		p := Payload{Url: domain + ":7051"}
+		b.applyTLS("peers", domain, &p)
+		got := p.TlsCACerts.Client
+		if (got == nil) != (want == nil) || got != nil && *got != *want {
+			t.Errorf("%s: got %+v, want %+v", domain, got, want)
+		}
+	}
+}
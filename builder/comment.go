@@ -0,0 +1,150 @@
+package builder
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/chaunsin/fgc/parse/host"
+	"github.com/chaunsin/fgc/parse/mspId"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	placeholderMspId = "{待替换}"
+	placeholderPin   = "${FABRIC_SDK_CLIENT_BCCSP_SECURITY_PIN}" // 未指定--pkcs11-pin时写入,运行sdk时由同名环境变量覆盖
+)
+
+// headComments 配置块以及字段说明,key为yaml路径,*匹配任意map key或者数组下标
+var headComments = map[string]string{
+	"version": "配置文件版本",
+	"client":  "客户端配置",
+	"organizations": "参与网络的组织,包含mspid、用户证书以及组织下的peer节点。\n" +
+		"其他组织的信息用于交易背书等流程,不包含其私有的用户证书",
+	"channels":               "通道配置,peers为通道中的peer节点及其角色,orderers为通道的排序节点,policies为通道策略",
+	"orderers":               "排序节点,url为grpc访问地址,tlsCACerts为排序组织的tls根证书",
+	"peers":                  "peer节点,url为grpc访问地址,tlsCACerts为peer组织的tls根证书",
+	"certificateAuthorities": "CA服务,用于动态注册、登记用户",
+	"entityMatchers": "地址映射,sdk访问节点时将匹配pattern的地址替换为urlSubstitutionExp,\n" +
+		"sslTargetOverrideUrlSubstitutionExp为校验tls证书使用的域名",
+	"operations": "运维服务配置",
+	"metrics":    "监控指标配置",
+
+	"client/organization":                    "客户端所属组织,需要与organizations中的key对应",
+	"client/logging":                         "sdk日志级别",
+	"client/cryptoconfig":                    "证书根目录,organizations中的cryptoPath相对该目录",
+	"client/credentialStore":                 "用户证书存储目录",
+	"client/BCCSP":                           "加密服务配置",
+	"client/BCCSP/security/default/provider": "SW:软件实现 PKCS11:硬件加密模块,需要配置library、pin、label",
+	"client/BCCSP/security/level":            "安全级别 256 384",
+	"client/BCCSP/tlsCerts":                  "双向tls客户端证书",
+	"organizations/*/mspid":                  "组织mspid,需要与configtx.yaml中的ID一致",
+	"organizations/*/cryptoPath":             "用户msp目录,{username}由sdk替换为实际用户名",
+	"organizations/*/peers":                  "组织下的peer节点",
+	"organizations/*/users":                  "用户证书,key为用户名",
+	"channels/*/peers":                       "通道中的peer节点,false表示不承担该角色",
+	"channels/*/orderers":                    "通道的排序节点",
+	"channels/*/policies":                    "通道策略,包括节点发现、节点选择、通道配置查询以及事件服务",
+	"orderers/*/grpcOptions":                 "grpc连接参数,ssl-target-name-override为校验tls证书使用的域名",
+	"peers/*/grpcOptions":                    "grpc连接参数,ssl-target-name-override为校验tls证书使用的域名",
+	"entityMatchers/*/*/urlSubstitutionExp":  "节点实际访问地址",
+}
+
+// note 记录配置值的来源说明,生成带注释的yaml时作为行尾注释,path中的*匹配任意map key
+func (b *Builder) note(comment string, path ...string) {
+	if b.notes == nil {
+		b.notes = make(map[string]string)
+	}
+	b.notes[strings.Join(path, "/")] = comment
+}
+
+// mspIdFrom mspid的来源说明
+func (b *Builder) mspIdFrom(org string) string {
+	if b.tx != nil {
+		if _, ok := b.tx.MspIds()[org]; ok {
+			return "来源: configtx.yaml"
+		}
+	}
+	if f, ok := b.host.(mspId.FetchMspId); ok {
+		if _, ok := f.GetMspId(org); ok {
+			return "来源: 容器环境变量"
+		}
+	}
+	if _, ok := b.mspId.GetMspId(org); ok {
+		return "来源: 默认值"
+	}
+	return ""
+}
+
+// matchPath 判断yaml路径是否匹配,pattern中的*匹配任意一段
+func matchPath(pattern string, path []string) bool {
+	list := strings.Split(pattern, "/")
+	if len(list) != len(path) {
+		return false
+	}
+	for i, p := range list {
+		if p != "*" && p != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup 查找匹配路径的注释,精确匹配优先,其次是*最少的模式,相同时按模式字典序,保证输出稳定
+func lookup(comments map[string]string, path []string) string {
+	if c, ok := comments[strings.Join(path, "/")]; ok {
+		return c
+	}
+	var patterns []string
+	for pattern := range comments {
+		if strings.Contains(pattern, "*") && matchPath(pattern, path) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return ""
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		ni, nj := strings.Count(patterns[i], "*"), strings.Count(patterns[j], "*")
+		if ni != nj {
+			return ni < nj
+		}
+		return patterns[i] < patterns[j]
+	})
+	return comments[patterns[0]]
+}
+
+// comment 为yaml节点增加配置说明、值来源以及占位符提示
+func (b *Builder) comment(node *yaml.Node) {
+	node.HeadComment = "fabric-sdk connection profile, generated by fgc\n" +
+		"包含" + host.PlaceholderIP + "、" + host.PlaceholderPort + "、" + placeholderMspId + "的值需要手动替换"
+	b.walk(node, nil)
+}
+
+func (b *Builder) walk(node *yaml.Node, path []string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			p := append(append([]string{}, path...), key.Value)
+			key.HeadComment = lookup(headComments, p)
+			line := lookup(b.notes, p)
+			if value.Kind == yaml.ScalarNode && (hasPlaceholder(value.Value) || value.Value == placeholderMspId) {
+				line = strings.TrimPrefix(line+"; 待替换", "; ")
+			}
+			if line != "" {
+				// 标量的注释放在值后面,其他类型放在key后面
+				if value.Kind == yaml.ScalarNode && value.Style&yaml.LiteralStyle == 0 {
+					value.LineComment = line
+				} else {
+					key.LineComment = line
+				}
+			}
+			b.walk(value, p)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			b.walk(item, append(append([]string{}, path...), "*"))
+		}
+	}
+}
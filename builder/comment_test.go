@@ -0,0 +1,60 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComment(t *testing.T) {
+	b := &Builder{
+		opts:    Options{Comments: true},
+		Version: "v1.0.0",
+		Client:  Client{Organization: "org1.example.com"},
+		Peers:   map[string]Payload{"peer0.org1.example.com": {Url: "peer0.org1.example.com:${PORT}"}},
+	}
+	b.note("来源: --org org1", "client", "organization")
+
+	data, err := b.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# 配置文件版本\nversion: v1.0.0",
+		"organization: org1.example.com # 来源: --org org1",
+		"url: peer0.org1.example.com:${PORT} # 待替换",
+		"# grpc连接参数",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("missing %q in:\n%s", want, data)
+		}
+	}
+
+	b.opts.Comments = false
+	if data, _ = b.YAML(); strings.Contains(string(data), "#") {
+		t.Fatalf("unexpected comment:\n%s", data)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	comments := map[string]string{
+		"channels/mychannel/policies": "exact",
+		"channels/*/policies":         "channel",
+		"*/mychannel/policies":        "section",
+		"*/*/policies":                "any",
+		"peers/*/grpcOptions":         "grpc",
+	}
+	for i := 0; i < 20; i++ {
+		for path, want := range map[string]string{
+			"channels/mychannel/policies": "exact",
+			"channels/ch2/policies":       "channel",
+			"orderers/mychannel/policies": "section",
+			"orderers/ch2/policies":       "any",
+			"peers/peer0/grpcOptions":     "grpc",
+			"peers/peer0/url":             "",
+		} {
+			if got := lookup(comments, strings.Split(path, "/")); got != want {
+				t.Fatalf("lookup(%s): got %q want %q", path, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package builder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ChangeAdded      = "added"
+	ChangeRemoved    = "removed"
+	ChangeChanged    = "changed"
+	ChangeUnreadable = "unreadable" // 路径方式的证书或私钥无法读取
+)
+
+// Change 两份配置之间的语义差异
+type Change struct {
+	Kind    string `json:"kind"`            // added removed changed
+	Section string `json:"section"`         // 配置块 organizations channels peers orderers entityMatchers client
+	Name    string `json:"name"`            // 组织、通道或者节点名称
+	Field   string `json:"field,omitempty"` // 变化的字段,为空表示整项增加或删除
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	var sign = map[string]string{ChangeAdded: "+", ChangeRemoved: "-", ChangeChanged: "~", ChangeUnreadable: "!"}[c.Kind]
+	s := fmt.Sprintf("%s %s %s", sign, c.Section, c.Name)
+	if c.Field != "" {
+		s += " " + c.Field
+	}
+	switch c.Kind {
+	case ChangeChanged:
+		s += fmt.Sprintf(": %s -> %s", c.Old, c.New)
+	case ChangeUnreadable:
+		s += ": " + strings.TrimPrefix(c.Old+"; "+c.New, "; ")
+	case ChangeAdded:
+		if c.New != "" {
+			s += ": " + c.New
+		}
+	case ChangeRemoved:
+		if c.Old != "" {
+			s += ": " + c.Old
+		}
+	}
+	return s
+}
+
+// Load 读取已有的fabric-sdk-go配置文件,支持yaml以及json
+func Load(path string) (*Builder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile:%w", err)
+	}
+	// json是yaml的子集,统一使用yaml解析,time.Duration同样支持5s格式
+	var b Builder
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("Unmarshal:%w", err)
+	}
+	b.dir = filepath.Dir(path)
+	return &b, nil
+}
+
+// Diff 比较两份配置,返回按配置块、名称、字段排序的语义差异
+// 包括组织、通道、节点的增删,地址、mspid变化以及证书轮换
+func Diff(old, new *Builder) []Change {
+	var d = differ{oldDir: old.dir, newDir: new.dir}
+
+	d.value("client", "client", "organization", old.Client.Organization, new.Client.Organization)
+
+	for _, name := range unionKeys(old.Organizations, new.Organizations) {
+		o, ok1 := old.Organizations[name]
+		n, ok2 := new.Organizations[name]
+		if !d.presence("organizations", name, ok1, ok2) {
+			continue
+		}
+		d.value("organizations", name, "mspid", o.MspId, n.MspId)
+		d.value("organizations", name, "cryptoPath", o.CryptoPath, n.CryptoPath)
+		d.list("organizations", name, "peers", o.Peers, n.Peers)
+		for _, user := range unionKeys(o.Users, n.Users) {
+			ou, ok1 := o.Users[user]
+			nu, ok2 := n.Users[user]
+			if !d.presence("organizations", name, ok1, ok2, "users."+user) {
+				continue
+			}
+			d.cert("organizations", name, "users."+user+".cert", ou.Cert.PemPath, nu.Cert.PemPath)
+			d.cert("organizations", name, "users."+user+".key", ou.Key.PemPath, nu.Key.PemPath)
+		}
+	}
+
+	for _, name := range unionKeys(old.Channels, new.Channels) {
+		o, ok1 := old.Channels[name]
+		n, ok2 := new.Channels[name]
+		if !d.presence("channels", name, ok1, ok2) {
+			continue
+		}
+		d.list("channels", name, "peers", sortedKeys(o.Peer), sortedKeys(n.Peer))
+		d.list("channels", name, "orderers", o.Orderers, n.Orderers)
+		for _, peer := range sortedKeys(n.Peer) {
+			if op, ok := o.Peer[peer]; ok && op != n.Peer[peer] {
+				d.value("channels", name, "peers."+peer, fmt.Sprintf("%+v", op), fmt.Sprintf("%+v", n.Peer[peer]))
+			}
+		}
+	}
+
+	d.nodes("orderers", old.Orderers, new.Orderers)
+	d.nodes("peers", old.Peers, new.Peers)
+
+	d.matchers("entityMatchers.peer", old.EntityMatchers.Peer, new.EntityMatchers.Peer)
+	d.matchers("entityMatchers.orderer", old.EntityMatchers.Orderer, new.EntityMatchers.Orderer)
+
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		a, b := d.changes[i], d.changes[j]
+		if a.Section != b.Section {
+			return a.Section < b.Section
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Field < b.Field
+	})
+	return d.changes
+}
+
+type differ struct {
+	changes        []Change
+	oldDir, newDir string // 相对证书路径的基准目录
+}
+
+func (d *differ) add(c Change) {
+	d.changes = append(d.changes, c)
+}
+
+// presence 记录整项的增删,两边都存在时返回true继续比较字段
+func (d *differ) presence(section, name string, inOld, inNew bool, field ...string) bool {
+	var f string
+	if len(field) > 0 {
+		f = field[0]
+	}
+	switch {
+	case inOld && !inNew:
+		d.add(Change{Kind: ChangeRemoved, Section: section, Name: name, Field: f})
+	case !inOld && inNew:
+		d.add(Change{Kind: ChangeAdded, Section: section, Name: name, Field: f})
+	}
+	return inOld && inNew
+}
+
+func (d *differ) value(section, name, field, old, new string) {
+	if old != new {
+		d.add(Change{Kind: ChangeChanged, Section: section, Name: name, Field: field, Old: old, New: new})
+	}
+}
+
+// list 比较无序列表,记录增加以及删除的元素
+func (d *differ) list(section, name, field string, old, new []string) {
+	var o, n = make(map[string]bool), make(map[string]bool)
+	for _, v := range old {
+		o[v] = true
+	}
+	for _, v := range new {
+		n[v] = true
+	}
+	for _, v := range sortedKeys(n) {
+		if !o[v] {
+			d.add(Change{Kind: ChangeAdded, Section: section, Name: name, Field: field, New: v})
+		}
+	}
+	for _, v := range sortedKeys(o) {
+		if !n[v] {
+			d.add(Change{Kind: ChangeRemoved, Section: section, Name: name, Field: field, Old: v})
+		}
+	}
+}
+
+// cert 比较证书或私钥的指纹,路径方式读取文件内容计算,同一路径下的证书轮换同样能够发现,
+// 文件无法读取时单独记录
+func (d *differ) cert(section, name, field string, old, new PemPath) {
+	o, oerr := fingerprint(old, d.oldDir)
+	n, nerr := fingerprint(new, d.newDir)
+	if oerr != nil || nerr != nil {
+		c := Change{Kind: ChangeUnreadable, Section: section, Name: name, Field: field}
+		if oerr != nil {
+			c.Old = oerr.Error()
+		}
+		if nerr != nil {
+			c.New = nerr.Error()
+		}
+		d.add(c)
+		return
+	}
+	if o != n {
+		d.add(Change{Kind: ChangeChanged, Section: section, Name: name, Field: field, Old: o, New: n})
+	}
+}
+
+func (d *differ) nodes(section string, old, new map[string]Payload) {
+	for _, name := range unionKeys(old, new) {
+		o, ok1 := old[name]
+		n, ok2 := new[name]
+		if !d.presence(section, name, ok1, ok2) {
+			continue
+		}
+		d.value(section, name, "url", o.Url, n.Url)
+		d.value(section, name, "grpcOptions.ssl-target-name-override", o.GrpcOptions.SSLTargetNameOverride, n.GrpcOptions.SSLTargetNameOverride)
+		d.cert(section, name, "tlsCACerts", o.TlsCACerts.PemPath, n.TlsCACerts.PemPath)
+	}
+}
+
+// matchers 按mappedHost比较地址映射
+func (d *differ) matchers(section string, old, new []Matcher) {
+	var o, n = make(map[string]Matcher), make(map[string]Matcher)
+	for _, m := range old {
+		o[m.MappedHost] = m
+	}
+	for _, m := range new {
+		n[m.MappedHost] = m
+	}
+	for _, name := range unionKeys(o, n) {
+		om, ok1 := o[name]
+		nm, ok2 := n[name]
+		if !d.presence(section, name, ok1, ok2) {
+			continue
+		}
+		d.value(section, name, "pattern", om.Pattern, nm.Pattern)
+		d.value(section, name, "urlSubstitutionExp", om.UrlSubstitutionExp, nm.UrlSubstitutionExp)
+	}
+}
+
+// fingerprint 证书取DER的sha256指纹,其他pem内容取文本的sha256,
+// 路径方式读取文件后同样计算,相对路径基于dir,都为空时返回空
+func fingerprint(p PemPath, dir string) (string, error) {
+	var data = []byte(strings.TrimSpace(p.Pem))
+	if p.Pem == "" {
+		if p.Path == "" {
+			return "", nil
+		}
+		path := p.Path
+		if !filepath.IsAbs(path) && dir != "" {
+			path = filepath.Join(dir, path)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		data = bytes.TrimSpace(raw)
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("SHA256:%x", sum[:8]), nil
+}
+
+// unionKeys 两个map key的并集,已排序
+func unionKeys(a, b interface{}) []string {
+	var seen = make(map[string]bool)
+	for _, k := range append(sortedKeys(a), sortedKeys(b)...) {
+		seen[k] = true
+	}
+	return sortedKeys(seen)
+}
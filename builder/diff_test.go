@@ -0,0 +1,116 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	const (
+		cert1 = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+		cert2 = "-----BEGIN CERTIFICATE-----\nMIIC\n-----END CERTIFICATE-----\n"
+	)
+	old := &Builder{
+		Organizations: map[string]OrgAndOrder{
+			"org1.example.com": {MspId: "Org1MSP", Peers: []string{"peer0.org1.example.com", "peer1.org1.example.com"}},
+		},
+		Peers: map[string]Payload{
+			"peer0.org1.example.com": {Url: "peer0.org1.example.com:7051", TlsCACerts: TLSCACerts{PemPath: PemPath{Pem: cert1}}},
+			"peer1.org1.example.com": {Url: "peer1.org1.example.com:8051"},
+		},
+	}
+	cur := &Builder{
+		Organizations: map[string]OrgAndOrder{
+			"org1.example.com": {MspId: "Org1MSPNew", Peers: []string{"peer0.org1.example.com"}},
+		},
+		Peers: map[string]Payload{
+			"peer0.org1.example.com": {Url: "peer0.org1.example.com:9051", TlsCACerts: TLSCACerts{PemPath: PemPath{Pem: cert2}}},
+		},
+	}
+
+	var got []string
+	for _, c := range Diff(old, cur) {
+		got = append(got, c.Kind+" "+c.Section+" "+c.Name+" "+c.Field)
+	}
+	want := []string{
+		"changed organizations org1.example.com mspid",
+		"removed organizations org1.example.com peers",
+		"changed peers peer0.org1.example.com tlsCACerts",
+		"changed peers peer0.org1.example.com url",
+		"removed peers peer1.org1.example.com ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+
+	if changes := Diff(old, old); len(changes) != 0 {
+		t.Fatalf("unexpected changes: %v", changes)
+	}
+}
+
+func TestDiffRotatedCert(t *testing.T) {
+	const (
+		cert1 = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+		cert2 = "-----BEGIN CERTIFICATE-----\nMIIC\n-----END CERTIFICATE-----\n"
+		rel   = "crypto-config/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem"
+	)
+	var (
+		oldDir = t.TempDir()
+		newDir = t.TempDir()
+	)
+	write := func(dir, content string) {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(oldDir, cert1)
+	write(newDir, cert1)
+
+	// 已有配置中为相对配置文件的路径
+	config := filepath.Join(oldDir, "config.yaml")
+	if err := os.WriteFile(config, []byte("peers:\n  peer0.org1.example.com:\n    url: peer0.org1.example.com:7051\n    tlsCACerts:\n      path: ./"+rel+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old, err := Load(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur := &Builder{Peers: map[string]Payload{
+		"peer0.org1.example.com": {Url: "peer0.org1.example.com:7051", TlsCACerts: TLSCACerts{PemPath: PemPath{Path: filepath.Join(newDir, rel)}}},
+	}}
+	if changes := Diff(old, cur); len(changes) != 0 {
+		t.Fatalf("same cert at a different root: unexpected changes %v", changes)
+	}
+
+	// 证书内容轮换,路径不变
+	write(newDir, cert2)
+	changes := Diff(old, cur)
+	if len(changes) != 1 || changes[0].Kind != ChangeChanged || changes[0].Field != "tlsCACerts" || changes[0].Old == changes[0].New {
+		t.Fatalf("rotated cert: got %v", changes)
+	}
+
+	// 证书内容方式与文件内容一致时没有差异
+	cur.Peers["peer0.org1.example.com"] = Payload{Url: "peer0.org1.example.com:7051", TlsCACerts: TLSCACerts{PemPath: PemPath{Pem: cert1}}}
+	if changes := Diff(old, cur); len(changes) != 0 {
+		t.Fatalf("inline pem of the same cert: unexpected changes %v", changes)
+	}
+
+	// 无法读取的文件单独记录
+	if err := os.Remove(filepath.Join(oldDir, rel)); err != nil {
+		t.Fatal(err)
+	}
+	changes = Diff(old, cur)
+	if len(changes) != 1 || changes[0].Kind != ChangeUnreadable || changes[0].Old == "" || changes[0].New != "" {
+		t.Fatalf("unreadable cert: got %v", changes)
+	}
+	if s := changes[0].String(); !strings.HasPrefix(s, "! peers peer0.org1.example.com tlsCACerts: ") {
+		t.Fatalf("String: %s", s)
+	}
+}
@@ -0,0 +1,258 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const envPrefix = "FABRIC"
+
+var (
+	envUnsafe = regexp.MustCompile(`[^A-Z0-9_]+`)
+	tomlBare  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// generic 将配置转换为与yaml输出一致的通用结构 map[string]interface{} []interface{} 以及标量
+func (b *Builder) generic() (interface{}, error) {
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("yaml: %w", err)
+	}
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("yaml: %w", err)
+	}
+	return v, nil
+}
+
+// flatten 按key排序深度遍历,数组下标作为路径的一段
+func flatten(v interface{}, path []string, fn func(path []string, value interface{})) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			flatten(v[k], append(append([]string{}, path...), k), fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flatten(item, append(append([]string{}, path...), strconv.Itoa(i)), fn)
+		}
+	default:
+		fn(path, v)
+	}
+}
+
+// scalar 标量转换为字符串,nil为空字符串
+func scalar(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// Env 生成环境变量格式,key为FABRIC_加路径大写,非字母数字替换为_,证书内容使用base64编码
+// eg: FABRIC_PEERS_PEER0_ORG1_EXAMPLE_COM_URL=peer0.org1.example.com:7051
+func (b *Builder) Env() ([]byte, error) {
+	v, err := b.generic()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	flatten(v, []string{envPrefix}, func(path []string, value interface{}) {
+		key := envUnsafe.ReplaceAllString(strings.ToUpper(strings.Join(path, "_")), "_")
+		val := scalar(value)
+		if strings.Contains(val, "-----BEGIN") {
+			val = base64.StdEncoding.EncodeToString([]byte(val))
+		}
+		// 包含特殊字符时使用单引号,单引号本身需要转义
+		if strings.ContainsAny(val, " \t\n\"'#$\\`") {
+			val = "'" + strings.ReplaceAll(val, "'", `'\''`) + "'"
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", key, val)
+	})
+	return buf.Bytes(), nil
+}
+
+// Properties 生成java properties格式,key为.分隔的路径
+// eg: peers.peer0.org1.example.com.url=peer0.org1.example.com:7051
+func (b *Builder) Properties() ([]byte, error) {
+	v, err := b.generic()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	flatten(v, nil, func(path []string, value interface{}) {
+		fmt.Fprintf(&buf, "%s=%s\n", propertiesEscape(strings.Join(path, "."), true), propertiesEscape(scalar(value), false))
+	})
+	return buf.Bytes(), nil
+}
+
+// propertiesEscape 转义properties中的特殊字符,key中还需要转义空格、:以及=
+func propertiesEscape(s string, key bool) string {
+	var buf strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case ' ':
+			if key || i == 0 {
+				buf.WriteString(`\ `)
+			} else {
+				buf.WriteRune(r)
+			}
+		case ':', '=', '#', '!':
+			if key {
+				buf.WriteRune('\\')
+			}
+			buf.WriteRune(r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// TOML 生成toml格式,结构与yaml一致
+func (b *Builder) TOML() ([]byte, error) {
+	v, err := b.generic()
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("toml: unexpected type %T", v)
+	}
+	var buf bytes.Buffer
+	writeTOML(&buf, m, nil)
+	return buf.Bytes(), nil
+}
+
+// writeTOML 先输出当前表的键值,再输出子表以及表数组
+func writeTOML(buf *bytes.Buffer, m map[string]interface{}, path []string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var tables, arrays []string
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case nil:
+			// toml没有null
+		case map[string]interface{}:
+			tables = append(tables, k)
+		case []interface{}:
+			if isTableArray(v) {
+				arrays = append(arrays, k)
+				continue
+			}
+			fmt.Fprintf(buf, "%s = %s\n", tomlKey(k), tomlValue(v))
+		default:
+			fmt.Fprintf(buf, "%s = %s\n", tomlKey(k), tomlValue(v))
+		}
+	}
+
+	for _, k := range tables {
+		p := append(append([]string{}, path...), k)
+		fmt.Fprintf(buf, "\n[%s]\n", tomlPath(p))
+		writeTOML(buf, m[k].(map[string]interface{}), p)
+	}
+	for _, k := range arrays {
+		p := append(append([]string{}, path...), k)
+		for _, item := range m[k].([]interface{}) {
+			fmt.Fprintf(buf, "\n[[%s]]\n", tomlPath(p))
+			writeTOML(buf, item.(map[string]interface{}), p)
+		}
+	}
+}
+
+// isTableArray 数组元素全部为map时输出为表数组
+func isTableArray(list []interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func tomlKey(k string) string {
+	if tomlBare.MatchString(k) {
+		return k
+	}
+	return tomlString(k)
+}
+
+func tomlPath(path []string) string {
+	list := make([]string, len(path))
+	for i, k := range path {
+		list[i] = tomlKey(k)
+	}
+	return strings.Join(list, ".")
+}
+
+func tomlValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return tomlString(v)
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, tomlValue(item))
+		}
+		return "[" + strings.Join(list, ", ") + "]"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// tomlString toml基本字符串
+func tomlString(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&buf, `\u%04X`, r)
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
@@ -0,0 +1,53 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFlat(t *testing.T) {
+	b := &Builder{
+		Version: "v1.0.0",
+		Peers: map[string]Payload{
+			"peer0.org1.example.com": {
+				Url:        "peer0.org1.example.com:${PORT}",
+				TlsCACerts: TLSCACerts{PemPath: PemPath{Pem: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"}},
+			},
+		},
+		EntityMatchers: EntityMatchers{Peer: []Matcher{{Pattern: `(\w*)peer0`, MappedHost: "peer0"}}},
+	}
+
+	for _, c := range []struct {
+		typ  string
+		want []string
+	}{
+		{"env", []string{
+			"FABRIC_VERSION=v1.0.0\n",
+			"FABRIC_PEERS_PEER0_ORG1_EXAMPLE_COM_URL='peer0.org1.example.com:${PORT}'\n",
+			"FABRIC_PEERS_PEER0_ORG1_EXAMPLE_COM_TLSCACERTS_PEM=LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0tCk1JSUIKLS0tLS1FTkQgQ0VSVElGSUNBVEUtLS0tLQo=\n",
+			"FABRIC_ENTITYMATCHERS_PEER_0_PATTERN='(\\w*)peer0'\n",
+		}},
+		{"properties", []string{
+			"version=v1.0.0\n",
+			"peers.peer0.org1.example.com.tlsCACerts.pem=-----BEGIN CERTIFICATE-----\\nMIIB\\n-----END CERTIFICATE-----\\n\n",
+			"entityMatchers.peer.0.pattern=(\\\\w*)peer0\n",
+		}},
+		{"toml", []string{
+			"version = \"v1.0.0\"\n",
+			"\n[peers.\"peer0.org1.example.com\"]\n",
+			"keep-alive-time = \"0s\"\n",
+			"\n[[entityMatchers.peer]]\nmappedHost = \"peer0\"\npattern = \"(\\\\w*)peer0\"\n",
+		}},
+	} {
+		b.opts.FileType = c.typ
+		data, err := b.Content()
+		if err != nil {
+			t.Fatalf("%s: %s", c.typ, err)
+		}
+		for _, want := range c.want {
+			if !strings.Contains(string(data), want) {
+				t.Fatalf("%s: missing %q in:\n%s", c.typ, want, data)
+			}
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultGrpc = "default"
+
+	// domainPlaceholder ssl-target-name-override中的节点域名占位符
+	domainPlaceholder = "{domain}"
+)
+
+// grpcKeys grpcOptions中可以配置的字段
+var grpcKeys = []string{"ssl-target-name-override", "allow-insecure", "fail-fast", "keep-alive-time", "keep-alive-timeout", "keep-alive-permit"}
+
+// grpcPresets grpc连接参数预设,ssl-target-name-override为{domain}时使用节点域名
+// default: 原有取值,不开启keep-alive
+// lan: 局域网,节点不可用时快速失败切换到其他节点
+// wan: 公网或跨机房,开启keep-alive避免空闲连接被中间设备断开
+var grpcPresets = map[string]GrpcOptions{
+	"default": {
+		SSLTargetNameOverride: domainPlaceholder,
+	},
+	"lan": {
+		SSLTargetNameOverride: domainPlaceholder,
+		FailFast:              true,
+	},
+	"wan": {
+		SSLTargetNameOverride: domainPlaceholder,
+		KeepAliveTime:         30 * time.Second,
+		KeepAliveTimeout:      20 * time.Second,
+		KeepAlivePermit:       true,
+	},
+}
+
+// grpcRule grpc参数规则,role以及pattern都为空时对所有节点生效
+type grpcRule struct {
+	role    string            // peer orderer ca
+	pattern string            // 节点域名通配符或者组织域名
+	values  map[string]string // grpcOptions字段 => 取值
+	from    string            // 规则来源,用于配置注释
+}
+
+// level 规则优先级,所有节点 < 角色 < 节点域名
+func (r grpcRule) level() int {
+	switch {
+	case r.pattern != "":
+		return 2
+	case r.role != "":
+		return 1
+	}
+	return 0
+}
+
+func (r grpcRule) match(role, domain string) bool {
+	switch {
+	case r.pattern != "":
+		matched, _ := path.Match(r.pattern, domain)
+		return matched || strings.HasSuffix(domain, "."+r.pattern)
+	case r.role != "":
+		return r.role == role
+	}
+	return true
+}
+
+// grpcConfig --grpc-config配置文件
+//
+//	default:
+//	  keep-alive-time: 30s
+//	peer:
+//	  fail-fast: true
+//	endpoints:
+//	  - pattern: "*.org2.example.com"
+//	    allow-insecure: true
+type grpcConfig struct {
+	Default   map[string]string `yaml:"default"`
+	Peer      map[string]string `yaml:"peer"`
+	Orderer   map[string]string `yaml:"orderer"`
+	CA        map[string]string `yaml:"ca"`
+	Endpoints []struct {
+		Pattern string            `yaml:"pattern"`
+		Values  map[string]string `yaml:",inline"`
+	} `yaml:"endpoints"`
+}
+
+// parseGrpcOpt 解析 [role|pattern:]key=value 格式的参数
+// eg: keep-alive-time=30s peer:fail-fast=true *.org2.example.com:allow-insecure=true
+func parseGrpcOpt(raw string) (grpcRule, error) {
+	kv := strings.SplitN(raw, "=", 2)
+	if len(kv) != 2 {
+		return grpcRule{}, fmt.Errorf("invalid grpc option %q, expect [role|pattern:]key=value", raw)
+	}
+	rule := grpcRule{from: "--grpc-opt " + raw}
+	key := strings.TrimSpace(kv[0])
+	if i := strings.LastIndex(key, ":"); i >= 0 {
+		rule = scopeRule(strings.TrimSpace(key[:i]), rule.from)
+		key = strings.TrimSpace(key[i+1:])
+	}
+	rule.values = map[string]string{key: strings.TrimSpace(kv[1])}
+	return rule, rule.valid()
+}
+
+// scopeRule peer orderer ca为角色,其他为节点域名通配符
+func scopeRule(scope, from string) grpcRule {
+	switch scope {
+	case "", "*":
+		return grpcRule{from: from}
+	case "peer", "orderer", "ca":
+		return grpcRule{role: scope, from: from}
+	}
+	return grpcRule{pattern: scope, from: from}
+}
+
+// valid 校验字段名称、通配符以及取值
+func (r grpcRule) valid() error {
+	if _, err := path.Match(r.pattern, ""); err != nil {
+		return fmt.Errorf("invalid grpc pattern %q: %w", r.pattern, err)
+	}
+	for key := range r.values {
+		if !contains(grpcKeys, key) {
+			return fmt.Errorf("unknown grpc option %q, expect %s", key, strings.Join(grpcKeys, "|"))
+		}
+	}
+	var o GrpcOptions
+	return r.apply(&o)
+}
+
+// apply 在已有参数上覆盖规则中的字段
+func (r grpcRule) apply(o *GrpcOptions) error {
+	var node = yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range sortedKeys(r.values) {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Value: r.values[key]}
+		// 空值表示清空字段,例如不生成ssl-target-name-override
+		if value.Value == "" {
+			value.Tag = "!!str"
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	if err := node.Decode(o); err != nil {
+		return fmt.Errorf("grpc option %s: %w", r.from, err)
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validGrpc 解析--grpc预设、--grpc-config文件以及--grpc-opt参数,相同优先级时参数覆盖文件
+func (b *Builder) validGrpc() error {
+	name := b.opts.Grpc
+	if name == "" {
+		name = defaultGrpc
+	}
+	if _, ok := grpcPresets[name]; !ok {
+		return fmt.Errorf("unknown grpc preset %q, expect %s", name, strings.Join(sortedKeys(grpcPresets), "|"))
+	}
+
+	var rules []grpcRule
+	if b.opts.GrpcFile != "" {
+		data, err := os.ReadFile(b.opts.GrpcFile)
+		if err != nil {
+			return fmt.Errorf("ReadFile:%w", err)
+		}
+		// 未知字段报错,避免字段名写错时配置不生效
+		var c grpcConfig
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("Decode:%w", err)
+		}
+		from := b.opts.GrpcFile
+		rules = append(rules,
+			grpcRule{values: c.Default, from: from},
+			grpcRule{role: "peer", values: c.Peer, from: from},
+			grpcRule{role: "orderer", values: c.Orderer, from: from},
+			grpcRule{role: "ca", values: c.CA, from: from},
+		)
+		for _, e := range c.Endpoints {
+			if e.Pattern == "" {
+				return fmt.Errorf("%s: endpoints pattern is empty", from)
+			}
+			rules = append(rules, grpcRule{pattern: e.Pattern, values: e.Values, from: from})
+		}
+	}
+	for _, raw := range b.opts.GrpcOpts {
+		rule, err := parseGrpcOpt(raw)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, rule)
+	}
+
+	b.grpcRules = b.grpcRules[:0]
+	for _, r := range rules {
+		if len(r.values) == 0 {
+			continue
+		}
+		if err := r.valid(); err != nil {
+			return err
+		}
+		b.grpcRules = append(b.grpcRules, r)
+	}
+	sort.SliceStable(b.grpcRules, func(i, j int) bool { return b.grpcRules[i].level() < b.grpcRules[j].level() })
+	return nil
+}
+
+// grpcOptions 节点的grpc连接参数,在预设基础上按 所有节点 < 角色 < 节点域名 的顺序覆盖,role为peer orderer ca
+func (b *Builder) grpcOptions(section, role, domain string) GrpcOptions {
+	name := b.opts.Grpc
+	if name == "" {
+		name = defaultGrpc
+	}
+	o := grpcPresets[name]
+	if name != defaultGrpc {
+		b.note("预设: "+name, section, domain, "grpcOptions")
+	}
+	for _, r := range b.grpcRules {
+		if r.match(role, domain) {
+			// 规则已经校验过
+			_ = r.apply(&o)
+			b.note("覆盖自 "+r.from, section, domain, "grpcOptions")
+		}
+	}
+	o.SSLTargetNameOverride = strings.ReplaceAll(o.SSLTargetNameOverride, domainPlaceholder, domain)
+	return o
+}
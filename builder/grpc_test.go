@@ -0,0 +1,67 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGrpcOptions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "grpc.yaml")
+	data := `default:
+  keep-alive-time: 10s
+orderer:
+  fail-fast: true
+endpoints:
+  - pattern: "*.org2.example.com"
+    ssl-target-name-override: ""
+`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b := &Builder{opts: Options{
+		Grpc:     "wan",
+		GrpcFile: file,
+		// 参数覆盖相同优先级的文件配置,节点规则优先于角色
+		GrpcOpts: []string{"peer1.org1.example.com:keep-alive-time=1m", "orderer:fail-fast=false", "example.com:fail-fast=true"},
+	}}
+	if err := b.validGrpc(); err != nil {
+		t.Fatal(err)
+	}
+
+	o := b.grpcOptions("peers", "peer", "peer0.org1.example.com")
+	if o.KeepAliveTime != 10*time.Second || o.KeepAliveTimeout != 20*time.Second || !o.KeepAlivePermit || o.SSLTargetNameOverride != "peer0.org1.example.com" {
+		t.Errorf("peer0.org1: %+v", o)
+	}
+	if o := b.grpcOptions("peers", "peer", "peer1.org1.example.com"); o.KeepAliveTime != time.Minute {
+		t.Errorf("peer1.org1: %+v", o)
+	}
+	if o := b.grpcOptions("peers", "peer", "peer0.org2.example.com"); o.SSLTargetNameOverride != "" {
+		t.Errorf("peer0.org2: %+v", o)
+	}
+	if o := b.grpcOptions("orderers", "orderer", "orderer.example.com"); !o.FailFast {
+		t.Errorf("orderer.example.com: %+v", o)
+	}
+	if o := b.grpcOptions("orderers", "orderer", "orderer0.ord2.com"); o.FailFast {
+		t.Errorf("orderer0.ord2.com: %+v", o)
+	}
+
+	// 文件中的未知字段
+	unknown := filepath.Join(t.TempDir(), "unknown.yaml")
+	if err := os.WriteFile(unknown, []byte("peers:\n  fail-fast: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range []Options{
+		{GrpcFile: unknown},
+		{Grpc: "fast"},
+		{GrpcOpts: []string{"foo=1"}},
+		{GrpcOpts: []string{"fail-fast=maybe"}},
+		{GrpcOpts: []string{"keep-alive-time"}},
+	} {
+		b := &Builder{opts: opts}
+		if err := b.validGrpc(); err == nil {
+			t.Errorf("%+v: expected error", opts)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	k8sUnsafe    = regexp.MustCompile(`[^a-z0-9-]+`)
+	k8sKeyUnsafe = regexp.MustCompile(`[^-._a-zA-Z0-9]+`)
+)
+
+// K8s kubernetes清单参数
+type K8s struct {
+	Name      string // 资源名称前缀,默认fabric
+	Namespace string // 命名空间,为空时不指定
+	MountPath string // 容器中的挂载目录,默认/etc/hyperledger/fabric
+	Kustomize bool   // 同时生成kustomization.yaml
+}
+
+// K8sFile 生成的清单文件
+type K8sFile struct {
+	Name   string
+	Data   []byte
+	Secret bool // 包含私钥,写入时权限为0600
+}
+
+type k8sMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type k8sObject struct {
+	ApiVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sMeta           `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data"`
+}
+
+type k8sKeyPath struct {
+	Key  string `yaml:"key"`
+	Path string `yaml:"path"`
+}
+
+type k8sSecretVolume struct {
+	SecretName  string       `yaml:"secretName"`
+	Items       []k8sKeyPath `yaml:"items"`
+	DefaultMode int          `yaml:"defaultMode"`
+}
+
+type k8sConfigMapVolume struct {
+	Name string `yaml:"name"`
+}
+
+type k8sVolume struct {
+	Name      string              `yaml:"name"`
+	Secret    *k8sSecretVolume    `yaml:"secret,omitempty"`
+	ConfigMap *k8sConfigMapVolume `yaml:"configMap,omitempty"`
+}
+
+type k8sVolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	SubPath   string `yaml:"subPath,omitempty"`
+	ReadOnly  bool   `yaml:"readOnly"`
+}
+
+// k8sGroup 挂载到同一目录的一组文件,对应一个Secret
+type k8sGroup struct {
+	dir   string            // 相对crypto-config的目录
+	files map[string]string // 相对dir的路径 => 源文件
+}
+
+// Kubernetes 生成ConfigMap(连接配置)以及Secret(证书和私钥)清单,只支持路径方式(--pem),
+// 配置中的证书路径改写为容器中的挂载路径,volumes.yaml为需要添加到pod中的volumes以及volumeMounts
+//
+//	用户msp目录以及双向tls客户端证书为kubernetes.io/tls类型,tls根证书为Opaque类型
+func (b *Builder) Kubernetes(k K8s) ([]K8sFile, error) {
+	if !b.opts.Pem {
+		return nil, fmt.Errorf("kubernetes manifests require path mode (--pem)")
+	}
+	// 连接配置写入ConfigMap,pin需要通过Secret中的环境变量设置
+	if b.opts.PKCS11Pin != "" {
+		return nil, fmt.Errorf("kubernetes manifests do not support --pkcs11-pin, set FABRIC_SDK_CLIENT_BCCSP_SECURITY_PIN from a Secret")
+	}
+	if k.Name == "" {
+		k.Name = "fabric"
+	}
+	if k.MountPath == "" {
+		k.MountPath = "/etc/hyperledger/fabric"
+	}
+	cryptoDir := path.Join(k.MountPath, "crypto-config")
+
+	files, err := b.cryptoFiles(func(rel string) string {
+		return path.Join(cryptoDir, rel)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cryptoFiles:%w", err)
+	}
+	b.Client.CryptoConfig.Path = cryptoDir
+	content, err := b.Content()
+	if err != nil {
+		return nil, fmt.Errorf("Content:%w", err)
+	}
+
+	var (
+		labels  = map[string]string{"app.kubernetes.io/managed-by": "fgc"}
+		meta    = func(name string) k8sMeta { return k8sMeta{Name: name, Namespace: k.Namespace, Labels: labels} }
+		cfgName = k.Name + "-config"
+		cfgFile = "config." + b.Ext()
+		cm      = k8sObject{ApiVersion: "v1", Kind: "ConfigMap", Metadata: meta(cfgName), Data: map[string]string{cfgFile: string(content)}}
+		volumes = []k8sVolume{{Name: "fabric-config", ConfigMap: &k8sConfigMapVolume{Name: cfgName}}}
+		// 使用subPath只挂载配置文件,避免覆盖挂载目录下的证书
+		mounts  = []k8sVolumeMount{{Name: "fabric-config", MountPath: path.Join(k.MountPath, cfgFile), SubPath: cfgFile, ReadOnly: true}}
+		secrets []interface{}
+	)
+
+	for i, g := range groupFiles(files) {
+		name := k8sName(k.Name + "-" + g.dir)
+		secret := k8sObject{ApiVersion: "v1", Kind: "Secret", Metadata: meta(name), Type: "Opaque", Data: make(map[string]string)}
+		vol := k8sVolume{Name: fmt.Sprintf("fabric-crypto-%d", i), Secret: &k8sSecretVolume{SecretName: name, DefaultMode: 0400}}
+
+		keys := g.keys()
+		if cert, key, ok := g.tlsPair(); ok {
+			secret.Type = "kubernetes.io/tls"
+			keys = map[string]string{"tls.crt": cert, "tls.key": key}
+		}
+		for _, key := range sortedKeys(keys) {
+			data, err := os.ReadFile(g.files[keys[key]])
+			if err != nil {
+				return nil, fmt.Errorf("ReadFile:%w", err)
+			}
+			secret.Data[key] = base64.StdEncoding.EncodeToString(data)
+			vol.Secret.Items = append(vol.Secret.Items, k8sKeyPath{Key: key, Path: keys[key]})
+		}
+		secrets = append(secrets, secret)
+		volumes = append(volumes, vol)
+		mounts = append(mounts, k8sVolumeMount{Name: vol.Name, MountPath: path.Join(cryptoDir, g.dir), ReadOnly: true})
+	}
+
+	cmData, err := marshalDocs([]interface{}{cm})
+	if err != nil {
+		return nil, fmt.Errorf("Marshal:%w", err)
+	}
+	secretData, err := marshalDocs(secrets)
+	if err != nil {
+		return nil, fmt.Errorf("Marshal:%w", err)
+	}
+	volData, err := marshalDocs([]interface{}{map[string]interface{}{"volumes": volumes, "volumeMounts": mounts}})
+	if err != nil {
+		return nil, fmt.Errorf("Marshal:%w", err)
+	}
+	list := []K8sFile{
+		{Name: "configmap.yaml", Data: cmData},
+		{Name: "secret.yaml", Data: secretData, Secret: true},
+		{Name: "volumes.yaml", Data: volData},
+	}
+	if k.Kustomize {
+		kust := map[string]interface{}{
+			"apiVersion": "kustomize.config.k8s.io/v1beta1",
+			"kind":       "Kustomization",
+			"resources":  []string{"configmap.yaml", "secret.yaml"},
+		}
+		if k.Namespace != "" {
+			kust["namespace"] = k.Namespace
+		}
+		data, err := marshalDocs([]interface{}{kust})
+		if err != nil {
+			return nil, fmt.Errorf("Marshal:%w", err)
+		}
+		list = append(list, K8sFile{Name: "kustomization.yaml", Data: data})
+	}
+	return list, nil
+}
+
+// groupFiles 按挂载目录分组,msp下的signcerts以及keystore合并到msp目录,其他文件按所在目录
+func groupFiles(files map[string]string) []k8sGroup {
+	var groups = make(map[string]*k8sGroup)
+	for rel, src := range files {
+		dir, name := path.Split(rel)
+		dir = strings.TrimSuffix(dir, "/")
+		if base := path.Base(dir); base == "signcerts" || base == "keystore" {
+			name = base + "/" + name
+			dir = path.Dir(dir)
+		}
+		g, ok := groups[dir]
+		if !ok {
+			g = &k8sGroup{dir: dir, files: make(map[string]string)}
+			groups[dir] = g
+		}
+		g.files[name] = src
+	}
+	var list = make([]k8sGroup, 0, len(groups))
+	for _, dir := range sortedKeys(groups) {
+		list = append(list, *groups[dir])
+	}
+	return list
+}
+
+// keys Secret的key => 挂载后的文件路径,key只能包含字母数字以及-._
+func (g k8sGroup) keys() map[string]string {
+	var keys = make(map[string]string, len(g.files))
+	for name := range g.files {
+		keys[k8sKeyUnsafe.ReplaceAllString(name, "_")] = name
+	}
+	return keys
+}
+
+// tlsPair 只包含一个证书以及一个私钥时返回两者挂载后的文件路径
+func (g k8sGroup) tlsPair() (cert, key string, ok bool) {
+	if len(g.files) != 2 {
+		return "", "", false
+	}
+	for name, src := range g.files {
+		if fileMode(src) == 0600 {
+			key = name
+		} else {
+			cert = name
+		}
+	}
+	return cert, key, cert != "" && key != ""
+}
+
+// k8sName 转换为合法的资源名称 eg: fabric-peer-org1-example-com-tlsca
+func k8sName(s string) string {
+	s = strings.NewReplacer("peerOrganizations", "peer", "ordererOrganizations", "orderer").Replace(s)
+	s = strings.Trim(k8sUnsafe.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(s) > 253 {
+		s = strings.TrimRight(s[:253], "-")
+	}
+	return s
+}
+
+// marshalDocs 多个对象输出为一个多文档yaml
+func marshalDocs(list []interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, v := range list {
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
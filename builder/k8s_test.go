@@ -0,0 +1,92 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestKubernetes(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "crypto-config")
+	org := filepath.Join(src, "peerOrganizations", "org1.example.com")
+	for _, name := range []string{
+		"tlsca/tlsca.org1.example.com-cert.pem",
+		"users/Admin@org1.example.com/msp/keystore/priv_sk",
+		"users/Admin@org1.example.com/msp/signcerts/Admin@org1.example.com-cert.pem",
+	} {
+		path := filepath.Join(org, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := &Builder{
+		opts: Options{Pem: true},
+		Organizations: map[string]OrgAndOrder{
+			"org1.example.com": {MspId: "Org1MSP", CryptoPath: "peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp"},
+		},
+		Peers: map[string]Payload{
+			"peer0.org1.example.com": {Url: "peer0.org1.example.com:7051", TlsCACerts: TLSCACerts{PemPath: PemPath{Path: filepath.Join(org, "tlsca/tlsca.org1.example.com-cert.pem")}}},
+		},
+	}
+	files, err := b.Kubernetes(K8s{Namespace: "fabric", Kustomize: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got = make(map[string]string)
+	for _, f := range files {
+		got[f.Name] = string(f.Data)
+	}
+	if len(got) != 4 {
+		t.Fatalf("files: %v", sortedKeys(got))
+	}
+	if !strings.Contains(got["configmap.yaml"], "/etc/hyperledger/fabric/crypto-config/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem") {
+		t.Errorf("path not rewritten:\n%s", got["configmap.yaml"])
+	}
+
+	var (
+		dec   = yaml.NewDecoder(strings.NewReader(got["secret.yaml"]))
+		types = make(map[string]string)
+	)
+	for {
+		var s k8sObject
+		if err := dec.Decode(&s); err != nil {
+			break
+		}
+		types[s.Metadata.Name] = s.Type
+		if s.Metadata.Namespace != "fabric" {
+			t.Errorf("%s namespace %q", s.Metadata.Name, s.Metadata.Namespace)
+		}
+	}
+	want := map[string]string{
+		"fabric-peer-org1-example-com-tlsca":                            "Opaque",
+		"fabric-peer-org1-example-com-users-admin-org1-example-com-msp": "kubernetes.io/tls",
+	}
+	if len(types) != len(want) {
+		t.Fatalf("secrets %v", types)
+	}
+	for name, typ := range want {
+		if types[name] != typ {
+			t.Errorf("%s type %q, want %q", name, types[name], typ)
+		}
+	}
+	if !strings.Contains(got["volumes.yaml"], "path: signcerts/Admin@org1.example.com-cert.pem") {
+		t.Errorf("msp items not mapped:\n%s", got["volumes.yaml"])
+	}
+
+	// pin不能写入ConfigMap
+	b.opts.PKCS11Pin = "98765432"
+	if _, err := b.Kubernetes(K8s{}); err == nil {
+		t.Error("expected error with --pkcs11-pin")
+	}
+
+	b.opts.Pem, b.opts.PKCS11Pin = false, ""
+	if _, err := b.Kubernetes(K8s{}); err == nil {
+		t.Error("expected error without --pem")
+	}
+}
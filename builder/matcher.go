@@ -0,0 +1,109 @@
+package builder
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/chaunsin/fgc/parse"
+	"github.com/chaunsin/fgc/parse/host"
+)
+
+const (
+	MatcherNode = "node" // 每个节点一条,地址替换为发现的ip以及端口(默认)
+	MatcherHost = "host" // 每个节点一条,只映射到mappedHost,使用节点配置中的url
+	MatcherOrg  = "org"  // 每个组织一条通配规则,保留原地址,使用组织第一个节点的配置
+)
+
+// matcherPattern 精确匹配域名,可选协议以及端口
+// eg: peer0.org1.example.com => ^(\w+://)?peer0\.org1\.example\.com(:\d+)?$
+func matcherPattern(domain string) string {
+	return `^(\w+://)?` + regexp.QuoteMeta(domain) + `(:\d+)?$`
+}
+
+// orgPattern 匹配组织下的任意节点,${1}为协议,${2}为节点名称,${3}为端口
+// eg: org1.example.com => ^(\w+://)?([\w-]+)\.org1\.example\.com(:\d+)?$
+func orgPattern(org string) string {
+	return `^(\w+://)?([\w-]+)\.` + regexp.QuoteMeta(org) + `(:\d+)?$`
+}
+
+// substitution 生成entityMatchers中的urlSubstitutionExp,未解析到ip或端口时记录
+func (b *Builder) substitution(kind, scheme, domain string, url host.Host) string {
+	exp := fmt.Sprintf("%s://%s:%s", scheme, url.IP(), url.Port())
+	if hasPlaceholder(exp) {
+		b.unresolve("entityMatchers."+kind, domain, "urlSubstitutionExp", exp)
+	}
+	return exp
+}
+
+// matchers 生成一组组织的地址映射,kind为peer或者orderer
+func (b *Builder) matchers(kind string, orgs map[parse.OrgName]*parse.Org) []Matcher {
+	var list = make([]Matcher, 0, len(orgs)*2)
+	for _, name := range sortedKeys(orgs) {
+		servers := orgs[parse.OrgName(name)].Servers()
+		if len(servers) == 0 {
+			continue
+		}
+		if b.opts.Matchers == MatcherOrg {
+			list = append(list, Matcher{
+				Pattern:                             orgPattern(name),
+				UrlSubstitutionExp:                  "${1}${2}." + name + "${3}",
+				SSLTargetOverrideUrlSubstitutionExp: "${2}." + name,
+				MappedHost:                          servers[0],
+			})
+			continue
+		}
+		for _, domain := range servers {
+			list = append(list, b.matcher(kind, b.scheme(domain), domain))
+		}
+	}
+	return list
+}
+
+// matcher 单个节点的地址映射
+func (b *Builder) matcher(kind, scheme, domain string) Matcher {
+	m := Matcher{
+		Pattern:    matcherPattern(domain),
+		MappedHost: domain,
+		MappedName: domain,
+	}
+	if b.opts.Matchers == MatcherHost {
+		return m
+	}
+	url, ok := b.host.GetHost(domain)
+	if !ok {
+		log.Printf("[entityMatchers] not found host: %s\n", domain)
+		url = host.Host(domain)
+	}
+	m.UrlSubstitutionExp = b.substitution(kind, scheme, domain, url)
+	m.SSLTargetOverrideUrlSubstitutionExp = domain
+	return m
+}
+
+// caMatchers 已生成的certificateAuthorities的地址映射,没有CA配置时不生成,避免指向不存在的CA
+func (b *Builder) caMatchers() []Matcher {
+	var list = make([]Matcher, 0, len(b.CertificateAuthorities))
+	for _, domain := range sortedKeys(b.CertificateAuthorities) {
+		scheme := "https"
+		if enabled, _ := b.tls(domain); !enabled {
+			scheme = "http"
+		}
+		list = append(list, b.matcher("certificateAuthority", scheme, domain))
+	}
+	return list
+}
+
+// entityMatchers 生成peer、排序节点以及开启--ca时CA服务的地址映射
+func (b *Builder) entityMatchers(cc *parse.CryptoConfig) error {
+	switch b.opts.Matchers {
+	case "", MatcherNode, MatcherHost, MatcherOrg:
+	default:
+		return fmt.Errorf("unknown matchers mode: %s", b.opts.Matchers)
+	}
+	b.EntityMatchers.Peer = b.matchers("peer", cc.Orgs)
+	b.EntityMatchers.Orderer = b.matchers("orderer", cc.Order)
+	if b.opts.CA {
+		b.EntityMatchers.CertificateAuthority = b.caMatchers()
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package builder
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestMatcherPattern(t *testing.T) {
+	re := regexp.MustCompile(matcherPattern("peer0.org1.example.com"))
+	for s, want := range map[string]bool{
+		"peer0.org1.example.com":               true,
+		"peer0.org1.example.com:7051":          true,
+		"grpcs://peer0.org1.example.com:7051":  true,
+		"peer0Xorg1.example.com:7051":          false,
+		"peer0.org1.example.com.cn:7051":       false,
+		"xpeer0.org1.example.com:7051":         false,
+		"peer0.org1.example.com:7051/fallback": false,
+	} {
+		if re.MatchString(s) != want {
+			t.Errorf("%s: want %v", s, want)
+		}
+	}
+
+	re = regexp.MustCompile(orgPattern("example.com"))
+	if got := re.ReplaceAllString("grpcs://orderer.example.com:7050", "${1}${2}.example.com${3}"); got != "grpcs://orderer.example.com:7050" {
+		t.Errorf("substitution: %s", got)
+	}
+	if re.MatchString("peer0.org1.example.com:7051") {
+		t.Error("org pattern should not match nested domain")
+	}
+}
+
+func TestCAMatchers(t *testing.T) {
+	// 未生成CA配置时不生成CA的地址映射
+	b := testBuilder(Options{CA: true}, partialHost)
+	if err := b.Build(testCrypto()); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.CertificateAuthorities) != 0 || len(b.EntityMatchers.CertificateAuthority) != 0 {
+		t.Fatalf("unexpected ca matchers: %+v", b.EntityMatchers.CertificateAuthority)
+	}
+
+	// 只为已生成的CA生成地址映射
+	b = testBuilder(Options{CA: true, EndpointTLS: []string{"ca.org2.example.com=off"}}, testHost{"ca.org2.example.com": "10.0.0.2:8054"})
+	if err := b.validTLS(); err != nil {
+		t.Fatal(err)
+	}
+	b.CertificateAuthorities["ca.org2.example.com"] = CertificateAuthorities{Url: "http://ca.org2.example.com:8054"}
+	list := b.caMatchers()
+	if len(list) != 1 || list[0].MappedHost != "ca.org2.example.com" || list[0].UrlSubstitutionExp != "http://10.0.0.2:8054" {
+		t.Fatalf("ca matchers: %+v", list)
+	}
+}
@@ -0,0 +1,125 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergeReplace 合并时以新生成内容为准的字段(证书、地址、节点等),*匹配任意map key
+var mergeReplace = []string{
+	"client/organization",
+	"client/BCCSP/tlsCerts/client",
+	"organizations/*/mspid",
+	"organizations/*/cryptoPath",
+	"organizations/*/peers",
+	"organizations/*/users",
+	"organizations/*/certificateAuthorities",
+	"channels/*/orderers",
+	"orderers/*/url",
+	"orderers/*/tlsCACerts",
+	"peers/*/url",
+	"peers/*/tlsCACerts",
+	"certificateAuthorities/*/url",
+	"certificateAuthorities/*/tlsCACerts",
+	"entityMatchers",
+}
+
+// mergeSync key集合以新生成内容为准的map,已有配置中多余的项会被删除
+var mergeSync = []string{
+	"organizations",
+	"orderers",
+	"peers",
+	"channels/*/peers",
+	"certificateAuthorities",
+}
+
+func matchAny(patterns []string, path []string) bool {
+	for _, p := range patterns {
+		if matchPath(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge 将新生成的配置合并到已有配置中,只刷新证书、地址、节点以及地址映射,
+// 保留手工修改的字段(通道策略、日志级别、grpc参数等)以及注释,返回合并后的内容
+func (b *Builder) Merge(existing []byte) ([]byte, error) {
+	var dst yaml.Node
+	if err := yaml.Unmarshal(existing, &dst); err != nil {
+		return nil, fmt.Errorf("Unmarshal:%w", err)
+	}
+	src, err := b.node()
+	if err != nil {
+		return nil, err
+	}
+	// 空文件直接使用新生成的内容
+	if len(dst.Content) == 0 {
+		dst = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{src}}
+	} else {
+		mergeNode(dst.Content[0], src, nil)
+	}
+
+	switch b.opts.FileType {
+	case "json":
+		var v interface{}
+		if err := dst.Decode(&v); err != nil {
+			return nil, fmt.Errorf("decode: %w", err)
+		}
+		return json.MarshalIndent(v, "", "  ")
+	default:
+		return encodeYAML(&dst)
+	}
+}
+
+// mergeNode 递归合并map,新生成的key追加到已有配置中,需要刷新的字段直接替换
+func mergeNode(dst, src *yaml.Node, path []string) {
+	if dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		return
+	}
+	var fresh = make(map[string]*yaml.Node, len(src.Content)/2)
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		fresh[src.Content[i].Value] = src.Content[i+1]
+	}
+	sync := matchAny(mergeSync, path)
+
+	var (
+		content = dst.Content[:0]
+		seen    = make(map[string]bool)
+	)
+	for i := 0; i+1 < len(dst.Content); i += 2 {
+		key, value := dst.Content[i], dst.Content[i+1]
+		p := append(append([]string{}, path...), key.Value)
+		n, ok := fresh[key.Value]
+		switch {
+		case !ok && (sync || matchAny(mergeReplace, p)):
+			// 新生成的配置中已经不存在
+			continue
+		case !ok:
+		case matchAny(mergeReplace, p):
+			value = keepComments(value, n)
+		default:
+			mergeNode(value, n, p)
+		}
+		seen[key.Value] = true
+		content = append(content, key, value)
+	}
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		if !seen[src.Content[i].Value] {
+			content = append(content, src.Content[i], src.Content[i+1])
+		}
+	}
+	dst.Content = content
+}
+
+// keepComments 替换字段时保留已有的注释,新生成内容带注释时以新内容为准
+func keepComments(old, new *yaml.Node) *yaml.Node {
+	if new.HeadComment == "" && new.LineComment == "" && new.FootComment == "" {
+		n := *new
+		n.HeadComment, n.LineComment, n.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		return &n
+	}
+	return new
+}
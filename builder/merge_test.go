@@ -0,0 +1,61 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMerge(t *testing.T) {
+	existing := `version: 1.0.0
+client:
+  organization: org1.example.com
+  logging:
+    level: debug # 手工调试
+channels:
+  mychannel:
+    peers:
+      peer0.org1.example.com:
+        endorsingPeer: true
+      peer9.gone.com:
+        endorsingPeer: true
+    policies:
+      queryChannelConfig:
+        maxTargets: 3
+peers:
+  peer0.org1.example.com:
+    url: peer0.org1.example.com:1111
+  peer9.gone.com:
+    url: peer9.gone.com:7051
+`
+	b := &Builder{
+		Version: "1.0.0",
+		Client:  Client{Organization: "org1.example.com"},
+		Channels: map[string]ChannelPeer{
+			"mychannel": {Peer: map[string]PeerPolicy{"peer0.org1.example.com": {EndorsingPeer: true}}},
+		},
+		Peers: map[string]Payload{
+			"peer0.org1.example.com": {Url: "peer0.org1.example.com:7051"},
+		},
+	}
+	data, err := b.Merge([]byte(existing))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, s := range []string{"level: debug # 手工调试", "maxTargets: 3", "peer0.org1.example.com:7051"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("missing %q in:\n%s", s, out)
+		}
+	}
+	for _, s := range []string{"peer9.gone.com", ":1111"} {
+		if strings.Contains(out, s) {
+			t.Errorf("unexpected %q in:\n%s", s, out)
+		}
+	}
+	var v map[string]interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+}
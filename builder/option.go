@@ -1,17 +1,42 @@
 package builder
 
 type Options struct {
-	Mode        string // 读取文件方式 local:默认 sftp ftp
-	OrgName     string // 组织名称
-	OrderName   string // 排序节点名称
-	ChannelName string // 排序组织名称
-	User        string // 用户名
-	Pem         bool   // 证书生成的格式 false:pem文件格式(默认) true:路径方式
-	DoubleTls   bool   // 生成tls false:单tls(默认) true:双tls
-	CA          bool   // 是否开启ca false:关闭(默认) true:开启
-	Metrics     bool   // 是否生成Metrics false:关闭(默认) true:开启
-	Operations  bool   // 是否生成Operations false:关闭(默认) true:开启
-	FileType    string // 生成文件类型 yaml(默认) json
+	Mode            string   // 读取文件方式 local:默认 sftp ftp
+	OrgName         string   // 组织名称
+	OrderName       string   // 排序节点名称
+	Channels        []string // 通道名称 name 或者 name:configtx中的profile,默认mychannel
+	ChannelOrgs     []string // 通道成员 name=org1,org2
+	ChannelFile     string   // 通道成员配置文件
+	ConfigTx        string   // configtx.yaml路径,根据profile生成通道成员
+	PeerRoles       []string // 节点通道角色规则 pattern=role,-role pattern为节点域名通配符或者组织
+	Policy          string   // 通道策略预设 default(默认) dev prod-ha
+	PolicyFile      string   // 通道策略配置文件,覆盖预设中的字段
+	Grpc            string   // grpc连接参数预设 default(默认) lan wan
+	GrpcFile        string   // grpc连接参数配置文件,按所有节点、角色、节点域名覆盖预设
+	GrpcOpts        []string // grpc连接参数 [role|pattern:]key=value,覆盖配置文件
+	LogLevel        string   // sdk日志级别 debug info(默认) warning error critical
+	StorePath       string   // 用户证书存储目录,默认./data/keystore
+	CryptoStorePath string   // 用户msp存储目录,默认./data/msp
+	Provider        string   // BCCSP provider SW(默认) PKCS11
+	HashAlgorithm   string   // 哈希算法 SHA2(默认) SHA3
+	SecurityLevel   int      // 安全级别 256(默认) 384
+	PKCS11Library   string   // PKCS11动态库路径,多个使用,分隔
+	PKCS11Pin       string   // PKCS11 pin,为空时写入占位符由环境变量FABRIC_SDK_CLIENT_BCCSP_SECURITY_PIN覆盖
+	PKCS11Label     string   // PKCS11 token label
+	Users           []string // 用户名列表,all表示所有用户,org=user单独指定组织的用户
+	Pem             bool     // 证书生成的格式 false:pem文件格式(默认) true:路径方式
+	DoubleTls       bool     // 生成tls false:单tls(默认) true:双tls
+	PeerClientTLS   []string // 节点单独的双向tls客户端证书 pattern=org,使用该组织选择的第一个用户的tls证书
+	NoTLS           bool     // 网络未开启tls,节点地址使用grpc://
+	EndpointTLS     []string // 节点tls规则 pattern=on|off pattern为节点域名通配符或者组织域名,未指定时从容器环境变量中检测
+	Matchers        string   // entityMatchers生成方式 node:每个节点替换地址(默认) host:只映射mappedHost org:每个组织一条通配规则
+	CA              bool     // 是否开启ca false:关闭(默认) true:开启
+	Metrics         bool     // 是否生成Metrics false:关闭(默认) true:开启
+	Operations      bool     // 是否生成Operations false:关闭(默认) true:开启
+	FileType        string   // 生成文件类型 yaml(默认) json env properties toml
+	Strict          bool     // 严格模式 存在未解析的服务地址时构建失败
+	Comments        bool     // 生成的yaml中增加配置说明注释
+	Template        string   // 内置模板名称(go java node)或者text/template模板文件路径,为空时按FileType生成
 
 	Language string
 }
@@ -0,0 +1,174 @@
+package builder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cryptoDirs crypto-config下组织所在目录
+var cryptoDirs = []string{"peerOrganizations", "ordererOrganizations"}
+
+// WriteTo 将生成的配置内容写入w,实现io.WriterTo
+func (b *Builder) WriteTo(w io.Writer) (int64, error) {
+	content, err := b.Content()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(content)
+	return int64(n), err
+}
+
+// Output 将完整配置写入dir,路径方式(--pem)时同时复制引用到的证书到dir/crypto-config并改写为相对路径,
+// 私钥文件权限为0600,目录结构见Bundle
+func (b *Builder) Output(dir string) error {
+	return b.Bundle(dir)
+}
+
+// ConfigMode 配置文件权限,证书内容方式(未指定--pem)时配置中内嵌私钥,指定了--pkcs11-pin时包含pin,权限为0600
+func (b *Builder) ConfigMode() os.FileMode {
+	if b.opts.Pem && b.opts.PKCS11Pin == "" {
+		return 0644
+	}
+	return 0600
+}
+
+// cryptoRoot 根据配置中的证书路径得到crypto-config根目录,所有路径必须位于同一个crypto-config中
+func (b *Builder) cryptoRoot() (string, error) {
+	var root string
+	err := b.eachPath(func(p *PemPath) error {
+		r, _, ok := splitCrypto(p.Path)
+		if !ok {
+			return fmt.Errorf("%s is not in crypto-config", p.Path)
+		}
+		if root != "" && root != r {
+			return fmt.Errorf("multiple crypto-config: %s %s", root, r)
+		}
+		root = r
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if root == "" {
+		return "", fmt.Errorf("crypto-config not found")
+	}
+	return root, nil
+}
+
+// eachPath 遍历配置中所有路径方式的证书以及私钥,fn可以修改路径,map中的值修改后写回
+func (b *Builder) eachPath(fn func(p *PemPath) error) error {
+	kc := func(v *KC) error {
+		if err := call(fn, &v.Key.PemPath); err != nil {
+			return err
+		}
+		return call(fn, &v.Cert.PemPath)
+	}
+	if err := kc(&b.Client.Bccsp.TLSCerts.Client); err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(b.Organizations) {
+		org := b.Organizations[name]
+		for _, user := range sortedKeys(org.Users) {
+			v := org.Users[user]
+			if err := kc(&v); err != nil {
+				return err
+			}
+			org.Users[user] = v
+		}
+	}
+	for _, m := range []map[string]Payload{b.Orderers, b.Peers} {
+		for _, k := range sortedKeys(m) {
+			v := m[k]
+			if err := call(fn, &v.TlsCACerts.PemPath); err != nil {
+				return err
+			}
+			if v.TlsCACerts.Client != nil {
+				c := *v.TlsCACerts.Client
+				if err := kc(&c); err != nil {
+					return err
+				}
+				v.TlsCACerts.Client = &c
+			}
+			m[k] = v
+		}
+	}
+	for _, k := range sortedKeys(b.CertificateAuthorities) {
+		v := b.CertificateAuthorities[k]
+		if err := call(fn, &v.TlsCACerts); err != nil {
+			return err
+		}
+		b.CertificateAuthorities[k] = v
+	}
+	return nil
+}
+
+// call 跳过证书内容方式以及空路径
+func call(fn func(p *PemPath) error, p *PemPath) error {
+	if p.Path == "" {
+		return nil
+	}
+	return fn(p)
+}
+
+// splitCrypto 将证书路径拆分为crypto-config根目录以及相对路径
+// eg: /data/crypto-config/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem
+// => /data/crypto-config peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem
+func splitCrypto(path string) (root, rel string, ok bool) {
+	list := strings.Split(filepath.ToSlash(path), "/")
+	for i := len(list) - 1; i >= 0; i-- {
+		for _, d := range cryptoDirs {
+			if list[i] == d {
+				return filepath.FromSlash(strings.Join(list[:i], "/")), filepath.FromSlash(strings.Join(list[i:], "/")), true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// copyFile 复制单个文件,按照文件类型设置权限
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return WriteFile(dst, data, fileMode(src))
+}
+
+// fileMode 私钥 keystore目录下的文件以及*.key、*_sk 权限为0600
+func fileMode(path string) os.FileMode {
+	name := filepath.Base(path)
+	if filepath.Base(filepath.Dir(path)) == "keystore" || strings.HasSuffix(name, "_sk") || strings.HasSuffix(name, ".key") {
+		return 0600
+	}
+	return 0644
+}
+
+// WriteFile 先写入同目录下的临时文件再重命名,避免写入失败时破坏已有文件
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,133 @@
+package builder
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	b := &Builder{
+		Organizations: map[string]OrgAndOrder{"org1.example.com": {MspId: "Org1MSP"}},
+		Peers: map[string]Payload{
+			"peer0.org1.example.com": {Url: "peer0.org1.example.com:7051", TlsCACerts: TLSCACerts{PemPath: PemPath{Path: "/data/crypto-config/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem"}}},
+		},
+	}
+	content, err := b.Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	n, err := b.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(n) != buf.Len() || !bytes.Equal(buf.Bytes(), content) {
+		t.Errorf("WriteTo differs from Content")
+	}
+
+	// 证书内容方式时配置中包含私钥
+	if mode := b.ConfigMode(); mode != 0600 {
+		t.Errorf("pem content mode %v, want 0600", mode)
+	}
+	b.opts.Pem = true
+	if mode := b.ConfigMode(); mode != 0644 {
+		t.Errorf("path mode %v, want 0644", mode)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, mode := range map[string]os.FileMode{
+		"tlsca/tlsca.org1.example.com-cert.pem":             0644,
+		"users/Admin@org1.example.com/msp/keystore/priv_sk": 0600,
+		"peers/peer0.org1.example.com/tls/server.key":       0600,
+	} {
+		if got := fileMode(name); got != mode {
+			t.Errorf("%s mode %v, want %v", name, got, mode)
+		}
+	}
+
+	path := filepath.Join(dir, "config.yaml")
+	if err := WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode %v, want 0600", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("content %q", data)
+	}
+	// 不遗留临时文件
+	if list, _ := os.ReadDir(dir); len(list) != 1 {
+		t.Errorf("temp files left: %v", list)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "crypto-config")
+	org := filepath.Join(src, "peerOrganizations", "org1.example.com")
+	for _, name := range []string{
+		"tlsca/tlsca.org1.example.com-cert.pem",
+		"users/Admin@org1.example.com/msp/keystore/priv_sk",
+		"users/Admin@org1.example.com/msp/signcerts/Admin@org1.example.com-cert.pem",
+		"users/Admin@org1.example.com/tls/client.key",
+	} {
+		path := filepath.Join(org, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := &Builder{
+		opts: Options{Pem: true},
+		Client: Client{Bccsp: Bccsp{TLSCerts: TLSCerts{Client: KC{
+			Key:  Key{PemPath{Path: filepath.Join(org, "users/Admin@org1.example.com/tls/client.key")}},
+			Cert: Cert{PemPath{Path: filepath.Join(org, "tlsca/tlsca.org1.example.com-cert.pem")}},
+		}}}},
+		Organizations: map[string]OrgAndOrder{
+			"org1.example.com": {MspId: "Org1MSP", CryptoPath: "peerOrganizations/org1.example.com/users/Admin@org1.example.com/msp"},
+		},
+		Peers: map[string]Payload{
+			"peer0.org1.example.com": {Url: "peer0.org1.example.com:7051", TlsCACerts: TLSCACerts{PemPath: PemPath{Path: filepath.Join(org, "tlsca/tlsca.org1.example.com-cert.pem")}}},
+		},
+	}
+	dir := t.TempDir()
+	if err := b.Output(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "crypto-config", "peerOrganizations", "org1.example.com")
+	for path, mode := range map[string]os.FileMode{
+		filepath.Join(dir, "config.yaml"):                                       0644,
+		filepath.Join(dst, "tlsca/tlsca.org1.example.com-cert.pem"):             0644,
+		filepath.Join(dst, "users/Admin@org1.example.com/msp/keystore/priv_sk"): 0600,
+		filepath.Join(dst, "users/Admin@org1.example.com/tls/client.key"):       0600,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("%s mode %v, want %v", path, info.Mode().Perm(), mode)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte("./crypto-config/peerOrganizations/org1.example.com/tlsca/tlsca.org1.example.com-cert.pem")) {
+		t.Errorf("config does not point to the copied crypto-config:\n%s", data)
+	}
+}
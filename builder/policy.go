@@ -0,0 +1,125 @@
+package builder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultPolicy = "default"
+
+// policyPresets 通道策略预设
+// default: fabric-sdk-go默认值
+// dev: 单机或少量节点的开发环境,减少重试快速失败
+// prod-ha: 多组织多节点的生产环境,更多的目标节点和重试并优先选择区块高度最高的节点
+var policyPresets = map[string]Policy{
+	"default": {
+		Discovery: Discovery{
+			MaxTargets: 2,
+			RetryOpts:  RetryOpts{Attempts: 4, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 5 * time.Second, BackoffFactor: 2.0},
+		},
+		Selection: Selection{
+			SortingStrategy:         "BlockHeightPriority",
+			Balancer:                "RoundRobin",
+			BlockHeightLagThreshold: 5,
+		},
+		QueryChannelConfig: QueryChannelConfig{
+			MinResponses: 1,
+			MaxTargets:   1,
+			RetryOpts:    RetryOpts{Attempts: 5, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 5 * time.Second, BackoffFactor: 2.0},
+		},
+		EventService: EventService{
+			ResolverStrategy:                 "PreferOrg",
+			Balancer:                         "RoundRobin",
+			BlockHeightLagThreshold:          5,
+			ReconnectBlockHeightLagThreshold: 8,
+			PeerMonitorPeriod:                6 * time.Second,
+		},
+	},
+	"dev": {
+		Discovery: Discovery{
+			MaxTargets: 1,
+			RetryOpts:  RetryOpts{Attempts: 2, InitialBackoff: 200 * time.Millisecond, MaxBackoff: time.Second, BackoffFactor: 2.0},
+		},
+		Selection: Selection{
+			SortingStrategy:         "BlockHeightPriority",
+			Balancer:                "RoundRobin",
+			BlockHeightLagThreshold: 5,
+		},
+		QueryChannelConfig: QueryChannelConfig{
+			MinResponses: 1,
+			MaxTargets:   1,
+			RetryOpts:    RetryOpts{Attempts: 2, InitialBackoff: 200 * time.Millisecond, MaxBackoff: time.Second, BackoffFactor: 2.0},
+		},
+		EventService: EventService{
+			ResolverStrategy:                 "PreferOrg",
+			Balancer:                         "RoundRobin",
+			BlockHeightLagThreshold:          5,
+			ReconnectBlockHeightLagThreshold: 10,
+			PeerMonitorPeriod:                10 * time.Second,
+		},
+	},
+	"prod-ha": {
+		Discovery: Discovery{
+			MaxTargets: 3,
+			RetryOpts:  RetryOpts{Attempts: 6, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 10 * time.Second, BackoffFactor: 2.0},
+		},
+		Selection: Selection{
+			SortingStrategy:         "BlockHeightPriority",
+			Balancer:                "RoundRobin",
+			BlockHeightLagThreshold: 3,
+		},
+		QueryChannelConfig: QueryChannelConfig{
+			MinResponses: 2,
+			MaxTargets:   3,
+			RetryOpts:    RetryOpts{Attempts: 6, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 10 * time.Second, BackoffFactor: 2.0},
+		},
+		EventService: EventService{
+			ResolverStrategy:                 "MinBlockHeight",
+			Balancer:                         "RoundRobin",
+			BlockHeightLagThreshold:          2,
+			ReconnectBlockHeightLagThreshold: 5,
+			PeerMonitorPeriod:                3 * time.Second,
+		},
+	},
+}
+
+// policy 根据预设生成通道策略,配置了策略文件时在预设基础上覆盖文件中声明的字段,文件中存在未知字段时返回错误
+func (b *Builder) policy() (Policy, error) {
+	name := b.opts.Policy
+	if name == "" {
+		name = defaultPolicy
+	}
+	p, ok := policyPresets[name]
+	if !ok {
+		var list = make([]string, 0, len(policyPresets))
+		for k := range policyPresets {
+			list = append(list, k)
+		}
+		sort.Strings(list)
+		return Policy{}, fmt.Errorf("unknown policy preset %q, expect %s", name, strings.Join(list, "|"))
+	}
+
+	b.note("预设: "+name, "channels", "*", "policies")
+	if b.opts.PolicyFile != "" {
+		b.note("预设: "+name+",覆盖自 "+b.opts.PolicyFile, "channels", "*", "policies")
+		data, err := os.ReadFile(b.opts.PolicyFile)
+		if err != nil {
+			return Policy{}, fmt.Errorf("ReadFile:%w", err)
+		}
+		// 未知字段报错,避免字段名写错时静默使用预设值
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+			return Policy{}, fmt.Errorf("Decode:%w", err)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,96 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicyPreset(t *testing.T) {
+	for name, check := range map[string]func(Policy) bool{
+		"": func(p Policy) bool {
+			return p.Discovery.MaxTargets == 2 && p.EventService.ResolverStrategy == "PreferOrg"
+		},
+		"default": func(p Policy) bool { return p.Discovery.MaxTargets == 2 && p.QueryChannelConfig.MinResponses == 1 },
+		"dev":     func(p Policy) bool { return p.Discovery.MaxTargets == 1 && p.Discovery.RetryOpts.Attempts == 2 },
+		"prod-ha": func(p Policy) bool {
+			return p.Discovery.MaxTargets == 3 && p.QueryChannelConfig.MinResponses == 2 && p.EventService.ResolverStrategy == "MinBlockHeight"
+		},
+	} {
+		p, err := testBuilder(Options{Policy: name}, testHost{}).policy()
+		if err != nil || !check(p) {
+			t.Fatalf("policy(%q): got %+v %v", name, p, err)
+		}
+	}
+
+	_, err := testBuilder(Options{Policy: "prod"}, testHost{}).policy()
+	if err == nil || !strings.Contains(err.Error(), "default|dev|prod-ha") {
+		t.Fatalf("policy(prod): got %v", err)
+	}
+
+	// Build时所有通道使用同一策略
+	b := testBuilder(Options{Policy: "dev", Channels: []string{"ch1", "ch2"}}, partialHost)
+	if err := b.Build(testCrypto()); err != nil {
+		t.Fatal(err)
+	}
+	for name, ch := range b.Channels {
+		if ch.Policy.Discovery.MaxTargets != 1 {
+			t.Fatalf("channel %s policy: %+v", name, ch.Policy)
+		}
+	}
+}
+
+func TestPolicyFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// 只覆盖文件中声明的字段,其余使用预设值
+	override := write("override.yaml", `
+discovery:
+  maxTargets: 5
+  retryOpts:
+    maxBackoff: 30s
+eventService:
+  resolverStrategy: Balanced
+`)
+	p, err := testBuilder(Options{Policy: "prod-ha", PolicyFile: override}, testHost{}).policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := policyPresets["prod-ha"]
+	want.Discovery.MaxTargets = 5
+	want.Discovery.RetryOpts.MaxBackoff = 30 * time.Second
+	want.EventService.ResolverStrategy = "Balanced"
+	if p != want {
+		t.Fatalf("policy:\ngot  %+v\nwant %+v", p, want)
+	}
+	// 预设不应被修改
+	if policyPresets["prod-ha"].Discovery.MaxTargets != 3 {
+		t.Fatal("preset prod-ha modified")
+	}
+
+	if p, err := testBuilder(Options{PolicyFile: write("empty.yaml", "")}, testHost{}).policy(); err != nil || p != policyPresets[defaultPolicy] {
+		t.Fatalf("policy(empty): got %+v %v", p, err)
+	}
+
+	for name, content := range map[string]string{
+		"unknown.yaml": "discovery:\n  maxTarget: 5\n",
+		"section.yaml": "selections:\n  balancer: Random\n",
+		"invalid.yaml": "discovery:\n  maxTargets: many\n",
+	} {
+		if _, err := testBuilder(Options{PolicyFile: write(name, content)}, testHost{}).policy(); err == nil {
+			t.Fatalf("policy(%s): expected error", name)
+		}
+	}
+	if _, err := testBuilder(Options{PolicyFile: filepath.Join(dir, "none.yaml")}, testHost{}).policy(); err == nil {
+		t.Fatal("policy(none.yaml): expected error")
+	}
+}
@@ -0,0 +1,124 @@
+package builder
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/chaunsin/fgc/parse"
+)
+
+// roleRule 节点通道角色规则 eg: peer1.org1.example.com=-endorsingPeer,-eventSource
+type roleRule struct {
+	pattern string          // 节点域名通配符、组织域名、组织简称或者mspid
+	roles   map[string]bool // 角色名称 => 是否开启
+}
+
+// parseRoleRule 解析 pattern=role,-role 格式的规则,角色前加-或!表示关闭,
+// 支持all、none以及committer(只记账不背书)
+func parseRoleRule(raw string) (roleRule, error) {
+	kv := strings.SplitN(raw, "=", 2)
+	if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+		return roleRule{}, fmt.Errorf("invalid peer role %q, expect pattern=role,-role", raw)
+	}
+	rule := roleRule{
+		pattern: strings.TrimSpace(kv[0]),
+		roles:   make(map[string]bool, 4),
+	}
+	if _, err := path.Match(rule.pattern, ""); err != nil {
+		return roleRule{}, fmt.Errorf("invalid peer role pattern %q: %w", rule.pattern, err)
+	}
+	for _, r := range strings.Split(kv[1], ",") {
+		r = strings.TrimSpace(r)
+		enable := true
+		if strings.HasPrefix(r, "-") || strings.HasPrefix(r, "!") {
+			enable, r = false, r[1:]
+		}
+		r = strings.TrimPrefix(r, "+")
+		switch strings.ToLower(r) {
+		case "":
+		case "endorsingpeer", "endorsing":
+			rule.roles["endorsingPeer"] = enable
+		case "chaincodequery":
+			rule.roles["chaincodeQuery"] = enable
+		case "ledgerquery":
+			rule.roles["ledgerQuery"] = enable
+		case "eventsource":
+			rule.roles["eventSource"] = enable
+		case "all", "none":
+			enable = enable == (strings.ToLower(r) == "all")
+			for _, name := range []string{"endorsingPeer", "chaincodeQuery", "ledgerQuery", "eventSource"} {
+				rule.roles[name] = enable
+			}
+		case "committer":
+			rule.roles["endorsingPeer"] = !enable
+			rule.roles["chaincodeQuery"] = !enable
+		default:
+			return roleRule{}, fmt.Errorf("invalid peer role %q, expect endorsingPeer|chaincodeQuery|ledgerQuery|eventSource|all|none|committer", r)
+		}
+	}
+	return rule, nil
+}
+
+// apply 规则命中时修改节点角色
+func (r roleRule) apply(p *PeerPolicy) {
+	for name, enable := range r.roles {
+		switch name {
+		case "endorsingPeer":
+			p.EndorsingPeer = enable
+		case "chaincodeQuery":
+			p.ChaincodeQuery = enable
+		case "ledgerQuery":
+			p.LedgerQuery = enable
+		case "eventSource":
+			p.EventSource = enable
+		}
+	}
+}
+
+// peerPolicies 计算每个peer节点的通道角色,默认全部开启,
+// 开启NodeOUs并且节点签名证书不是peer角色时无法满足背书策略,默认不作为背书节点,
+// 最后按--peer-role规则依次覆盖,后面的规则优先
+func (b *Builder) peerPolicies(cc *parse.CryptoConfig) (map[string]PeerPolicy, error) {
+	var rules = make([]roleRule, 0, len(b.opts.PeerRoles))
+	for _, raw := range b.opts.PeerRoles {
+		rule, err := parseRoleRule(raw)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	var resp = make(map[string]PeerPolicy)
+	for orgName, org := range cc.Orgs {
+		for domain, serve := range org.Server {
+			p := PeerPolicy{
+				EndorsingPeer:  true,
+				ChaincodeQuery: true,
+				LedgerQuery:    true,
+				EventSource:    true,
+			}
+
+			role, err := serve.Msp.Role()
+			if err != nil {
+				log.Printf("[peerPolicies] %s nodeOU role: %s\n", domain, err)
+			}
+			if role != "" && role != "peer" {
+				log.Printf("[peerPolicies] %s nodeOU role is %s, not endorsing peer\n", domain, role)
+				p.EndorsingPeer = false
+				b.note("NodeOU角色为"+role+",不作为背书节点", "channels", "*", "peers", string(domain))
+			}
+
+			for _, rule := range rules {
+				matched, _ := path.Match(rule.pattern, string(domain))
+				if matched || b.matchOrg(orgName, rule.pattern) {
+					rule.apply(&p)
+					b.note("--peer-role "+rule.pattern, "channels", "*", "peers", string(domain))
+				}
+			}
+			resp[string(domain)] = p
+		}
+	}
+	return resp, nil
+}
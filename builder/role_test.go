@@ -0,0 +1,160 @@
+package builder
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/fgc/parse"
+)
+
+func TestParseRoleRule(t *testing.T) {
+	var cases = []struct {
+		raw  string
+		want PeerPolicy
+	}{
+		{"peer1.*=-endorsingPeer,!eventSource", PeerPolicy{ChaincodeQuery: true, LedgerQuery: true}},
+		{"org1=committer", PeerPolicy{LedgerQuery: true, EventSource: true}},
+		{"org1=none,+eventSource", PeerPolicy{EventSource: true}},
+	}
+	for _, c := range cases {
+		rule, err := parseRoleRule(c.raw)
+		if err != nil {
+			t.Fatalf("parseRoleRule(%q): %s", c.raw, err)
+		}
+		got := PeerPolicy{EndorsingPeer: true, ChaincodeQuery: true, LedgerQuery: true, EventSource: true}
+		rule.apply(&got)
+		if got != c.want {
+			t.Fatalf("parseRoleRule(%q): got %+v want %+v", c.raw, got, c.want)
+		}
+	}
+
+	for _, raw := range []string{"peer0", "=eventSource", "peer0=anchor", "[=eventSource"} {
+		if _, err := parseRoleRule(raw); err == nil {
+			t.Fatalf("parseRoleRule(%q): expected error", raw)
+		}
+	}
+}
+
+// nodeOUMsp 生成包含NodeOUs配置以及指定OU签名证书的msp
+func nodeOUMsp(t *testing.T, ou string, enable bool) parse.Msp {
+	dir := t.TempDir()
+	config := fmt.Sprintf(`NodeOUs:
+  Enable: %v
+  ClientOUIdentifier:
+    OrganizationalUnitIdentifier: client
+  PeerOUIdentifier:
+    OrganizationalUnitIdentifier: peer
+  AdminOUIdentifier:
+    OrganizationalUnitIdentifier: admin
+  OrdererOUIdentifier:
+    OrganizationalUnitIdentifier: orderer
+`, enable)
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: ou, OrganizationalUnit: []string{ou}},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return parse.Msp{
+		ConfigYaml: parse.Package{Yaml: parse.File(filepath.Join(dir, "config.yaml"))},
+		SignCerts:  parse.Package{Cert: parse.File(cert)},
+	}
+}
+
+func TestPeerPolicies(t *testing.T) {
+	cc := testCrypto()
+	org1, org2 := cc.Orgs["org1.example.com"], cc.Orgs["org2.example.com"]
+	org1.Server["peer0.org1.example.com"].Msp = nodeOUMsp(t, "peer", true)
+	org1.Server["peer1.org1.example.com"].Msp = nodeOUMsp(t, "client", true)
+	org2.Server["peer0.org2.example.com"].Msp = nodeOUMsp(t, "admin", true)
+	org2.Server["peer1.org2.example.com"] = &parse.Serve{Msp: nodeOUMsp(t, "orderer", true)}
+	org2.Server["peer2.org2.example.com"] = &parse.Serve{Msp: nodeOUMsp(t, "client", false)}
+
+	var (
+		all    = PeerPolicy{EndorsingPeer: true, ChaincodeQuery: true, LedgerQuery: true, EventSource: true}
+		noEndo = PeerPolicy{ChaincodeQuery: true, LedgerQuery: true, EventSource: true}
+	)
+	for _, c := range []struct {
+		name  string
+		rules []string
+		want  map[string]PeerPolicy
+	}{
+		{
+			// NodeOU角色不是peer时不作为背书节点,未开启NodeOUs时默认全部开启
+			name: "nodeOU",
+			want: map[string]PeerPolicy{
+				"peer0.org1.example.com": all,
+				"peer1.org1.example.com": noEndo,
+				"peer0.org2.example.com": noEndo,
+				"peer1.org2.example.com": noEndo,
+				"peer2.org2.example.com": all,
+			},
+		},
+		{
+			// 规则按节点通配符、组织简称、组织域名以及mspid匹配
+			name:  "scope",
+			rules: []string{"Org1MSP=-eventSource", "org2=committer", "peer1.org1.example.com=endorsingPeer", "*.org2.example.com=-ledgerQuery", "org3=none"},
+			want: map[string]PeerPolicy{
+				"peer0.org1.example.com": {EndorsingPeer: true, ChaincodeQuery: true, LedgerQuery: true},
+				"peer1.org1.example.com": {EndorsingPeer: true, ChaincodeQuery: true, LedgerQuery: true},
+				"peer0.org2.example.com": {EventSource: true},
+				"peer1.org2.example.com": {EventSource: true},
+				"peer2.org2.example.com": {EventSource: true},
+			},
+		},
+		{
+			// 后面的规则优先
+			name:  "order",
+			rules: []string{"org1.example.com=none", "peer0.org1.example.com=all", "peer*.org2.example.com=-eventSource", "org2=eventSource"},
+			want: map[string]PeerPolicy{
+				"peer0.org1.example.com": all,
+				"peer1.org1.example.com": {},
+				"peer0.org2.example.com": noEndo,
+				"peer1.org2.example.com": noEndo,
+				"peer2.org2.example.com": all,
+			},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			b := testBuilder(Options{PeerRoles: c.rules}, partialHost)
+			if err := b.Build(cc); err != nil {
+				t.Fatal(err)
+			}
+			got := b.Channels[defaultChannel].Peer
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("channels.%s.peers:\ngot  %+v\nwant %+v", defaultChannel, got, c.want)
+			}
+		})
+	}
+
+	if _, err := testBuilder(Options{PeerRoles: []string{"org1=anchor"}}, partialHost).peerPolicies(cc); err == nil {
+		t.Fatal("peerPolicies: expected error for unknown role")
+	}
+}
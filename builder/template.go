@@ -0,0 +1,283 @@
+package builder
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// builtinTemplates 内置模板名称 => 模板文件
+var builtinTemplates = map[string]string{
+	"go":   "templates/go.yaml.tmpl",
+	"java": "templates/java.yaml.tmpl",
+	"node": "templates/node.json.tmpl",
+}
+
+// View 模板渲染使用的视图,字段保持稳定,列表均已排序
+type View struct {
+	Version        string         // 配置版本
+	Language       string         // 目标sdk语言 golang java nodejs
+	Client         ViewClient     // 客户端
+	Orgs           []ViewOrg      // peer组织以及排序组织
+	Peers          []ViewNode     // peer节点
+	Orderers       []ViewNode     // 排序节点
+	Channels       []ViewChannel  // 通道
+	EntityMatchers EntityMatchers // 地址映射
+}
+
+// ViewClient 客户端配置
+type ViewClient struct {
+	Organization string  // 客户端所属组织,对应Orgs中的Name
+	CryptoPath   string  // 证书根目录,--pem路径方式时有值
+	TLS          *ViewKC // 双向tls客户端证书,未开启--tls时为nil
+	Logging      Logging
+	Store        CredentialStore
+	Security     Security
+}
+
+// ViewKC 私钥以及证书
+type ViewKC struct {
+	Key  PemPath
+	Cert PemPath
+}
+
+// ViewUser 组织用户
+type ViewUser struct {
+	Name string // 用户名 Admin User1
+	ViewKC
+}
+
+// ViewOrg 组织
+type ViewOrg struct {
+	Name       string     // organizations中的key
+	MspId      string     // mspid
+	Orderer    bool       // 是否为排序组织
+	CryptoPath string     // 用户msp目录,--pem路径方式时有值
+	Peers      []string   // 组织下的peer节点域名
+	Users      []ViewUser // 用户证书,--pem路径方式时为空
+}
+
+// ViewNode peer或者排序节点
+type ViewNode struct {
+	Name        string      // 节点域名
+	Org         string      // 所属组织,对应Orgs中的Name
+	MspId       string      // 所属组织mspid
+	Url         string      // grpc访问地址 host:port,不带协议
+	TLS         bool        // 是否开启tls
+	Scheme      string      // 访问协议 grpcs grpc
+	TLSCACert   PemPath     // tls根证书,未开启tls时为空
+	ClientTLS   *ViewKC     // 节点单独的双向tls客户端证书,未指定--peer-client-tls时为nil
+	GrpcOptions GrpcOptions // grpc连接参数
+}
+
+// ViewChannelPeer 通道中的peer节点以及角色
+type ViewChannelPeer struct {
+	Name string
+	PeerPolicy
+}
+
+// ViewChannel 通道
+type ViewChannel struct {
+	Name     string
+	Peers    []ViewChannelPeer
+	Orderers []string
+	Policy   Policy
+}
+
+// View 根据构建结果生成模板视图,需要在Build之后调用
+func (b *Builder) View() View {
+	v := View{
+		Version:        b.Version,
+		Language:       b.opts.Language,
+		EntityMatchers: b.EntityMatchers,
+		Client: ViewClient{
+			Organization: b.Client.Organization,
+			CryptoPath:   b.Client.CryptoConfig.Path,
+			Logging:      b.Client.Logging,
+			Store:        b.Client.CredentialStore,
+			Security:     b.Client.Bccsp.Security,
+		},
+	}
+	if b.opts.DoubleTls {
+		v.Client.TLS = &ViewKC{
+			Key:  b.Client.Bccsp.TLSCerts.Client.Key.PemPath,
+			Cert: b.Client.Bccsp.TLSCerts.Client.Cert.PemPath,
+		}
+	}
+
+	for _, name := range sortedKeys(b.Organizations) {
+		org := b.Organizations[name]
+		o := ViewOrg{
+			Name:       name,
+			MspId:      org.MspId,
+			Orderer:    b.ownsOrderer(name),
+			CryptoPath: org.CryptoPath,
+			Peers:      org.Peers,
+		}
+		for _, user := range sortedKeys(org.Users) {
+			kc := org.Users[user]
+			o.Users = append(o.Users, ViewUser{Name: user, ViewKC: ViewKC{Key: kc.Key.PemPath, Cert: kc.Cert.PemPath}})
+		}
+		v.Orgs = append(v.Orgs, o)
+	}
+
+	node := func(domain string, p Payload) ViewNode {
+		org := b.owner[domain]
+		n := ViewNode{
+			Name:        domain,
+			Org:         org,
+			MspId:       b.Organizations[org].MspId,
+			Url:         strings.TrimPrefix(p.Url, "grpc://"),
+			TLS:         !strings.HasPrefix(p.Url, "grpc://"),
+			Scheme:      "grpcs",
+			TLSCACert:   p.TlsCACerts.PemPath,
+			GrpcOptions: p.GrpcOptions,
+		}
+		if c := p.TlsCACerts.Client; c != nil {
+			n.ClientTLS = &ViewKC{Key: c.Key.PemPath, Cert: c.Cert.PemPath}
+		}
+		if !n.TLS {
+			n.Scheme = "grpc"
+		}
+		return n
+	}
+	for _, domain := range sortedKeys(b.Peers) {
+		v.Peers = append(v.Peers, node(domain, b.Peers[domain]))
+	}
+	for _, domain := range sortedKeys(b.Orderers) {
+		v.Orderers = append(v.Orderers, node(domain, b.Orderers[domain]))
+	}
+
+	for _, name := range sortedKeys(b.Channels) {
+		ch := b.Channels[name]
+		c := ViewChannel{Name: name, Orderers: ch.Orderers, Policy: ch.Policy}
+		for _, domain := range sortedKeys(ch.Peer) {
+			c.Peers = append(c.Peers, ViewChannelPeer{Name: domain, PeerPolicy: ch.Peer[domain]})
+		}
+		v.Channels = append(v.Channels, c)
+	}
+	return v
+}
+
+// ownsOrderer 判断组织下是否有排序节点
+func (b *Builder) ownsOrderer(org string) bool {
+	for domain, o := range b.owner {
+		if _, ok := b.Orderers[domain]; ok && o == org {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys 返回排序后的map key,m必须是key为string的map
+func sortedKeys(m interface{}) []string {
+	v := reflect.ValueOf(m)
+	list := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		list = append(list, k.String())
+	}
+	sort.Strings(list)
+	return list
+}
+
+// templateFuncs 模板辅助函数
+// pem: 证书内容,路径方式时读取文件
+// path: 证书路径
+// indent: 每行增加n个空格
+// b64: base64编码
+// quote: json字符串转义
+var templateFuncs = template.FuncMap{
+	"pem": func(p PemPath) (string, error) {
+		if p.Pem != "" || p.Path == "" {
+			return p.Pem, nil
+		}
+		data, err := os.ReadFile(p.Path)
+		if err != nil {
+			return "", fmt.Errorf("ReadFile:%w", err)
+		}
+		return string(data), nil
+	},
+	"path": func(p PemPath) string {
+		return p.Path
+	},
+	"indent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+		for i, l := range lines {
+			if l != "" {
+				lines[i] = pad + l
+			}
+		}
+		return strings.Join(lines, "\n")
+	},
+	"b64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"quote": func(s string) (string, error) {
+		data, err := json.Marshal(s)
+		return string(data), err
+	},
+}
+
+// loadTemplate 加载内置模板或者模板文件,返回模板以及生成文件的后缀
+func loadTemplate(name string) (*template.Template, string, error) {
+	var (
+		file = name
+		data []byte
+		err  error
+	)
+	if f, ok := builtinTemplates[name]; ok {
+		file = f
+		data, err = templates.ReadFile(f)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("ReadFile:%w", err)
+	}
+
+	t, err := template.New(filepath.Base(file)).Funcs(templateFuncs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("Parse:%w", err)
+	}
+	// config.yaml.tmpl => yaml
+	ext := strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(filepath.Base(file), ".tmpl")), ".")
+	return t, ext, nil
+}
+
+// Template 使用--template指定的内置模板或者模板文件渲染配置
+func (b *Builder) Template() ([]byte, error) {
+	t, _, err := loadTemplate(b.opts.Template)
+	if err != nil {
+		return nil, fmt.Errorf("loadTemplate:%w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, b.View()); err != nil {
+		return nil, fmt.Errorf("Execute:%w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Ext 生成文件的后缀,使用模板时由模板文件名决定 eg: node.json.tmpl => json
+func (b *Builder) Ext() string {
+	if b.opts.Template != "" {
+		if _, ext, err := loadTemplate(b.opts.Template); err == nil && ext != "" {
+			return ext
+		}
+	}
+	if b.opts.FileType == "" {
+		return "yaml"
+	}
+	return b.opts.FileType
+}
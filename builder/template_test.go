@@ -0,0 +1,66 @@
+package builder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	b := &Builder{
+		Version: "v1.0.0",
+		Client:  Client{Organization: "org1.example.com"},
+		Organizations: map[string]OrgAndOrder{
+			"org1.example.com": {MspId: "Org1MSP", Peers: []string{"peer0.org1.example.com"}},
+		},
+		Peers: map[string]Payload{
+			"peer0.org1.example.com": {Url: "peer0.org1.example.com:7051", TlsCACerts: TLSCACerts{PemPath: PemPath{Pem: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"}}},
+		},
+		Channels: map[string]ChannelPeer{
+			"mychannel": {Peer: map[string]PeerPolicy{"peer0.org1.example.com": {EndorsingPeer: true}}},
+		},
+		owner: map[string]string{"peer0.org1.example.com": "org1.example.com"},
+	}
+
+	b.opts.Template = "node"
+	data, err := b.Content()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var profile struct {
+		Peers map[string]struct {
+			Url        string `json:"url"`
+			TlsCACerts struct {
+				Pem string `json:"pem"`
+			} `json:"tlsCACerts"`
+		} `json:"peers"`
+	}
+	if err := json.Unmarshal(data, &profile); err != nil {
+		t.Fatalf("invalid json: %s\n%s", err, data)
+	}
+	peer := profile.Peers["peer0.org1.example.com"]
+	if peer.Url != "grpcs://peer0.org1.example.com:7051" || peer.TlsCACerts.Pem == "" {
+		t.Fatalf("unexpected peer: %+v", peer)
+	}
+	if ext := b.Ext(); ext != "json" {
+		t.Fatalf("Ext: got %s want json", ext)
+	}
+
+	// 用户模板文件
+	file := filepath.Join(t.TempDir(), "peers.txt.tmpl")
+	tmpl := `{{ range .Peers }}{{ .Name }} {{ .MspId }} {{ pem .TLSCACert | b64 }}{{ end }}`
+	if err := os.WriteFile(file, []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b.opts.Template = file
+	if data, err = b.Content(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "peer0.org1.example.com Org1MSP LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0tCk1JSUIKLS0tLS1FTkQgQ0VSVElGSUNBVEUtLS0tLQo="; string(data) != want {
+		t.Fatalf("got %q want %q", data, want)
+	}
+	if ext := b.Ext(); ext != "txt" {
+		t.Fatalf("Ext: got %s want txt", ext)
+	}
+}
@@ -0,0 +1,93 @@
+package builder
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/chaunsin/fgc/parse/host"
+)
+
+// tlsRule 节点tls规则 eg: peer0.org1.example.com=off
+type tlsRule struct {
+	pattern string // 节点域名通配符或者组织域名
+	enabled bool
+}
+
+// parseTLSRule 解析 pattern=on|off 格式的规则
+func parseTLSRule(raw string) (tlsRule, error) {
+	kv := strings.SplitN(raw, "=", 2)
+	if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+		return tlsRule{}, fmt.Errorf("invalid endpoint tls %q, expect pattern=on|off", raw)
+	}
+	rule := tlsRule{pattern: strings.TrimSpace(kv[0])}
+	if _, err := path.Match(rule.pattern, ""); err != nil {
+		return tlsRule{}, fmt.Errorf("invalid endpoint tls pattern %q: %w", rule.pattern, err)
+	}
+	switch strings.ToLower(strings.TrimSpace(kv[1])) {
+	case "on", "true", "enable":
+		rule.enabled = true
+	case "off", "false", "disable":
+	default:
+		return tlsRule{}, fmt.Errorf("invalid endpoint tls %q, expect on|off", kv[1])
+	}
+	return rule, nil
+}
+
+// match 匹配节点域名或者节点所属的组织域名
+func (r tlsRule) match(domain string) bool {
+	matched, _ := path.Match(r.pattern, domain)
+	return matched || strings.HasSuffix(domain, "."+r.pattern)
+}
+
+// validTLS 解析--endpoint-tls规则
+func (b *Builder) validTLS() error {
+	b.tlsRules = make([]tlsRule, 0, len(b.opts.EndpointTLS))
+	for _, raw := range b.opts.EndpointTLS {
+		rule, err := parseTLSRule(raw)
+		if err != nil {
+			return err
+		}
+		b.tlsRules = append(b.tlsRules, rule)
+	}
+	return nil
+}
+
+// tls 节点是否开启tls以及判断依据,优先级 --endpoint-tls(后面的规则优先) > --no-tls > 容器环境变量 > 默认开启
+func (b *Builder) tls(domain string) (enabled bool, from string) {
+	for i := len(b.tlsRules) - 1; i >= 0; i-- {
+		if r := b.tlsRules[i]; r.match(domain) {
+			return r.enabled, "--endpoint-tls " + r.pattern
+		}
+	}
+	if b.opts.NoTLS {
+		return false, "--no-tls"
+	}
+	if f, ok := b.host.(host.FetchTLS); ok {
+		if enabled, ok := f.GetTLS(domain); ok {
+			return enabled, "容器环境变量"
+		}
+	}
+	return true, ""
+}
+
+// scheme 节点访问协议,未开启tls时为grpc
+func (b *Builder) scheme(domain string) string {
+	if enabled, _ := b.tls(domain); !enabled {
+		return "grpc"
+	}
+	return "grpcs"
+}
+
+// applyTLS 未开启tls的节点地址使用grpc://,允许非tls连接并且不需要tls根证书
+func (b *Builder) applyTLS(section, domain string, p *Payload) {
+	enabled, from := b.tls(domain)
+	if enabled {
+		b.applyClientTLS(section, domain, p)
+		return
+	}
+	p.Url = "grpc://" + p.Url
+	p.GrpcOptions.AllowInsecure = true
+	p.TlsCACerts = TLSCACerts{}
+	b.note("未开启tls,来源: "+from, section, domain, "url")
+}
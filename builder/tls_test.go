@@ -0,0 +1,54 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/chaunsin/fgc/parse/host"
+)
+
+// tlsHost 模拟从容器环境变量中检测到的tls状态
+type tlsHost map[string]bool
+
+func (h tlsHost) GetHost(domain string) (host.Host, bool) { return host.Host(domain + ":7051"), true }
+func (h tlsHost) Close() error                            { return nil }
+func (h tlsHost) GetTLS(domain string) (bool, bool) {
+	enabled, ok := h[domain]
+	return enabled, ok
+}
+
+func TestTLS(t *testing.T) {
+	b := &Builder{
+		host: tlsHost{"peer0.org1.example.com": false, "peer1.org1.example.com": true},
+		opts: Options{EndpointTLS: []string{"*.org2.example.com=off", "peer1.org2.example.com=on", "peer1.org1.example.com=off"}},
+	}
+	if err := b.validTLS(); err != nil {
+		t.Fatal(err)
+	}
+	for domain, want := range map[string]bool{
+		"peer0.org1.example.com": false, // 容器环境变量
+		"peer1.org1.example.com": false, // 规则优先于容器环境变量
+		"peer0.org2.example.com": false,
+		"peer1.org2.example.com": true, // 后面的规则优先
+		"orderer.example.com":    true, // 默认开启
+	} {
+		if got, _ := b.tls(domain); got != want {
+			t.Errorf("%s: got %v, want %v", domain, got, want)
+		}
+	}
+
+	p := Payload{Url: "peer0.org1.example.com:7051", TlsCACerts: TLSCACerts{PemPath: PemPath{Path: "tlsca.pem"}}}
+	b.applyTLS("peers", "peer0.org1.example.com", &p)
+	if p.Url != "grpc://peer0.org1.example.com:7051" || !p.GrpcOptions.AllowInsecure || p.TlsCACerts.Path != "" {
+		t.Errorf("applyTLS: %+v", p)
+	}
+
+	b.opts.NoTLS = true
+	if got, _ := b.tls("orderer.example.com"); got {
+		t.Error("--no-tls should disable tls")
+	}
+	for _, raw := range []string{"peer0", "peer0=maybe", "[=off"} {
+		if _, err := parseTLSRule(raw); err == nil {
+			t.Errorf("%s: expected error", raw)
+		}
+	}
+}
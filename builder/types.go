@@ -27,9 +27,12 @@ type Security struct {
 	Default struct {
 		Provider string `json:"provider,omitempty" yaml:"provider"`
 	} `json:"default,omitempty" yaml:"default"`
-	HashAlgorithm string `json:"hash_algorithm,omitempty" yaml:"hashAlgorithm"`
-	SoftVerify    bool   `json:"soft_verify,omitempty" yaml:"softVerify"`
+	HashAlgorithm string `json:"hashAlgorithm,omitempty" yaml:"hashAlgorithm"`
+	SoftVerify    bool   `json:"softVerify,omitempty" yaml:"softVerify"`
 	Level         int64  `json:"level,omitempty" yaml:"level"`
+	Library       string `json:"library,omitempty" yaml:"library,omitempty"` // PKCS11动态库,多个路径使用,分隔
+	Pin           string `json:"pin,omitempty" yaml:"pin,omitempty"`         // PKCS11 pin
+	Label         string `json:"label,omitempty" yaml:"label,omitempty"`     // PKCS11 token label
 }
 
 type Key struct {
@@ -46,13 +49,13 @@ type KC struct {
 }
 
 type TLSCerts struct {
-	SystemCertPool bool `json:"system_cert_pool,omitempty" yaml:"systemCertPool"`
+	SystemCertPool bool `json:"systemCertPool,omitempty" yaml:"systemCertPool"`
 	Client         KC   `json:"client,omitempty" yaml:"client"`
 }
 
 type Bccsp struct {
 	Security Security `json:"security" yaml:"security"`
-	TLSCerts TLSCerts `json:"tls_certs,omitempty" yaml:"tlsCerts,omitempty"`
+	TLSCerts TLSCerts `json:"tlsCerts,omitempty" yaml:"tlsCerts,omitempty"`
 }
 
 type PemPath struct {
@@ -90,10 +93,10 @@ func (p PemPath) ToGolangPath() string {
 }
 
 type PeerPolicy struct {
-	EndorsingPeer  bool `json:"endorsing_peer" yaml:"endorsingPeer"`
-	ChaincodeQuery bool `json:"chaincode_query" yaml:"chaincodeQuery"`
-	LedgerQuery    bool `json:"ledger_query" yaml:"ledgerQuery"`
-	EventSource    bool `json:"event_source" yaml:"eventSource"`
+	EndorsingPeer  bool `json:"endorsingPeer" yaml:"endorsingPeer"`
+	ChaincodeQuery bool `json:"chaincodeQuery" yaml:"chaincodeQuery"`
+	LedgerQuery    bool `json:"ledgerQuery" yaml:"ledgerQuery"`
+	EventSource    bool `json:"eventSource" yaml:"eventSource"`
 }
 
 type RetryOpts struct {
@@ -109,9 +112,9 @@ type Discovery struct {
 }
 
 type Selection struct {
-	SortingStrategy         string `json:"sortingStrategy,omitempty" yaml:"SortingStrategy"`
-	Balancer                string `json:"balancer,omitempty" yaml:"Balancer"`
-	BlockHeightLagThreshold int    `json:"blockHeightLagThreshold,omitempty" yaml:"BlockHeightLagThreshold"`
+	SortingStrategy         string `json:"sortingStrategy,omitempty" yaml:"sortingStrategy"`
+	Balancer                string `json:"balancer,omitempty" yaml:"balancer"`
+	BlockHeightLagThreshold int    `json:"blockHeightLagThreshold,omitempty" yaml:"blockHeightLagThreshold"`
 }
 
 type QueryChannelConfig struct {
@@ -136,65 +139,71 @@ type Policy struct {
 }
 
 type ChannelPeer struct {
-	Peer   map[string]PeerPolicy `json:"peer,omitempty" yaml:"peers,omitempty"`    // key为组织名称
-	Order  []string              `json:"order,omitempty" yaml:"order,omitempty"`   // todo: golang中早期配置中可以配置order通道但是后期v1.0.0sdk配置会提示废弃因此此处不生成
-	Policy Policy                `json:"policy,omitempty" yaml:"policy,omitempty"` // 策略
+	Peer     map[string]PeerPolicy `json:"peers,omitempty" yaml:"peers,omitempty"`       // key为节点域名
+	Orderers []string              `json:"orderers,omitempty" yaml:"orderers,omitempty"` // 通道的排序节点,只有配置了通道排序组织时生成
+	Policy   Policy                `json:"policies,omitempty" yaml:"policies,omitempty"` // 策略
 }
 
 type OrgAndOrder struct {
-	MspId                  string        `json:"msp_id,omitempty" yaml:"mspid"`
-	CryptoPath             string        `json:"crypto_path,omitempty" yaml:"cryptoPath,omitempty"`
+	MspId                  string        `json:"mspid,omitempty" yaml:"mspid"`
+	CryptoPath             string        `json:"cryptoPath,omitempty" yaml:"cryptoPath,omitempty"`
 	Peers                  []string      `json:"peers,omitempty" yaml:"peers,omitempty"`
-	CertificateAuthorities []string      `json:"certificate_authorities,omitempty" yaml:"certificateAuthorities,omitempty"`
+	CertificateAuthorities []string      `json:"certificateAuthorities,omitempty" yaml:"certificateAuthorities,omitempty"`
 	Users                  map[string]KC `json:"users" yaml:"users,omitempty"`
 }
 
 type GrpcOptions struct {
-	SSLTargetNameOverride string        `json:"ssl_target_name_override,omitempty" yaml:"ssl-target-name-override"`
-	AllowInsecure         bool          `json:"allow_insecure,omitempty" yaml:"allow-insecure"`
-	FailFast              bool          `json:"fail_fast,omitempty" yaml:"fail-fast"`
-	KeepAliveTime         time.Duration `json:"keep_alive_time,omitempty" yaml:"keep-alive-time"`
-	KeepAliveTimeout      time.Duration `json:"keep_alive_timeout,omitempty" yaml:"keep-alive-timeout"`
-	KeepAlivePermit       time.Duration `json:"keep_alive_permit" yaml:"keep-alive-permit"`
+	SSLTargetNameOverride string        `json:"ssl-target-name-override,omitempty" yaml:"ssl-target-name-override,omitempty"`
+	AllowInsecure         bool          `json:"allow-insecure,omitempty" yaml:"allow-insecure"`
+	FailFast              bool          `json:"fail-fast,omitempty" yaml:"fail-fast"`
+	KeepAliveTime         time.Duration `json:"keep-alive-time,omitempty" yaml:"keep-alive-time"`
+	KeepAliveTimeout      time.Duration `json:"keep-alive-timeout,omitempty" yaml:"keep-alive-timeout"`
+	KeepAlivePermit       bool          `json:"keep-alive-permit" yaml:"keep-alive-permit"`
 }
 
 type Payload struct {
 	Url         string      `json:"url,omitempty" yaml:"url"`
 	GrpcOptions GrpcOptions `json:"grpcOptions,omitempty" yaml:"grpcOptions"`
-	TlsCACerts  PemPath     `json:"tls_ca_certs,omitempty" yaml:"tlsCACerts"`
+	TlsCACerts  TLSCACerts  `json:"tlsCACerts,omitempty" yaml:"tlsCACerts,omitempty"`
+}
+
+// TLSCACerts 节点tls根证书,Client为节点单独使用的双向tls客户端证书(--peer-client-tls)
+type TLSCACerts struct {
+	PemPath `yaml:",inline"`
+	Client  *KC `json:"client,omitempty" yaml:"client,omitempty"`
 }
 
 type CertificateAuthoritiesTLSCACerts struct {
-	PemPath `yaml:"path,inline"`
-	Client  KC `yaml:"client"`
+	PemPath `yaml:",inline"`
+	Client  KC `json:"client" yaml:"client"`
 }
 
 type Registrar struct {
-	EnrollId     string `json:"enroll_id,omitempty" yaml:"enrollId"`
-	EnrollSecret string `json:"enroll_secret,omitempty" yaml:"enroll_secret"`
+	EnrollId     string `json:"enrollId,omitempty" yaml:"enrollId"`
+	EnrollSecret string `json:"enrollSecret,omitempty" yaml:"enrollSecret"`
 }
 
 type CertificateAuthorities struct {
 	Url         string      `json:"url,omitempty" yaml:"url"`
 	GrpcOptions GrpcOptions `json:"grpcOptions,omitempty" yaml:"grpcOptions"`
-	TlsCACerts  PemPath     `json:"tls_ca_certs,omitempty" yaml:"tlsCACerts"`
+	TlsCACerts  PemPath     `json:"tlsCACerts,omitempty" yaml:"tlsCACerts"`
 	Registrar   Registrar   `json:"registrar,omitempty" yaml:"registrar"`
-	CaName      string      `json:"ca_name,omitempty" yaml:"caName"`
+	CaName      string      `json:"caName,omitempty" yaml:"caName"`
 }
 
 type Matcher struct {
 	Pattern                             string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
-	UrlSubstitutionExp                  string `json:"url_substitution_exp,omitempty" yaml:"urlSubstitutionExp,omitempty"`
-	SSLTargetOverrideUrlSubstitutionExp string `json:"ssl_target_override_url_substitution_exp,omitempty" yaml:"sslTargetOverrideUrlSubstitutionExp,omitempty"`
-	MappedHost                          string `json:"mapped_host,omitempty" yaml:"mappedHost,omitempty"`
-	MappedName                          string `json:"mapped_name,omitempty" yaml:"mappedName,omitempty"`         // TODO:读golang源码发现
-	IgnoreEndpoint                      bool   `json:"ignore_endpoint,omitempty" yaml:"ignoreEndpoint,omitempty"` // TODO:读golang源码发现
+	UrlSubstitutionExp                  string `json:"urlSubstitutionExp,omitempty" yaml:"urlSubstitutionExp,omitempty"`
+	SSLTargetOverrideUrlSubstitutionExp string `json:"sslTargetOverrideUrlSubstitutionExp,omitempty" yaml:"sslTargetOverrideUrlSubstitutionExp,omitempty"`
+	MappedHost                          string `json:"mappedHost,omitempty" yaml:"mappedHost,omitempty"`
+	MappedName                          string `json:"mappedName,omitempty" yaml:"mappedName,omitempty"`         // 映射到的实体名称
+	IgnoreEndpoint                      bool   `json:"ignoreEndpoint,omitempty" yaml:"ignoreEndpoint,omitempty"` // 忽略匹配到的节点
 }
 
 type EntityMatchers struct {
 	Peer                 []Matcher `json:"peer,omitempty" yaml:"peer,omitempty"`
 	Orderer              []Matcher `json:"orderer,omitempty" yaml:"orderer,omitempty"`
-	CertificateAuthority []Matcher `json:"certificate_authority" yaml:"certificateAuthority,omitempty"`
+	CertificateAuthority []Matcher `json:"certificateAuthority" yaml:"certificateAuthority,omitempty"`
 }
 
 type OperationsTLS struct {
@@ -205,21 +214,21 @@ type OperationsTLS struct {
 	Key struct {
 		File string `json:"file,omitempty" yaml:"file"`
 	} `json:"key,omitempty" yaml:"key"`
-	ClientAuthRequired bool `json:"client_auth_required,omitempty" yaml:"clientAuthRequired"`
+	ClientAuthRequired bool `json:"clientAuthRequired,omitempty" yaml:"clientAuthRequired"`
 	ClientRootCAs      struct {
 		Files []string `json:"files,omitempty" yaml:"files"`
 	}
 }
 
 type Operations struct {
-	ListenAddress string        `json:"listen_address,omitempty" yaml:"listenAddress"`
+	ListenAddress string        `json:"listenAddress,omitempty" yaml:"listenAddress"`
 	Tls           OperationsTLS `json:"tls,omitempty" yaml:"tls"`
 }
 
 type Statsd struct {
 	Network       string        `json:"network,omitempty" yaml:"network"`
 	Address       string        `json:"address,omitempty" yaml:"address"`
-	WriteInterval time.Duration `json:"write_interval,omitempty" yaml:"writeInterval"`
+	WriteInterval time.Duration `json:"writeInterval,omitempty" yaml:"writeInterval"`
 	Prefix        string        `json:"prefix,omitempty" yaml:"prefix"`
 }
 
@@ -237,9 +246,17 @@ type Client struct {
 }
 
 type Builder struct {
-	opts  Options
-	host  host.FetchHost
-	mspId mspId.FetchMspId
+	opts       Options
+	host       host.FetchHost
+	mspId      mspId.FetchMspId
+	tlsRules   []tlsRule    // --endpoint-tls规则
+	grpcRules  []grpcRule   // --grpc-config以及--grpc-opt规则,按优先级排序
+	clientTLS  []clientTLS  // --peer-client-tls规则以及对应的客户端证书
+	unresolved []Unresolved // 未解析出真实地址的服务
+	tx         *parse.ConfigTx
+	notes      map[string]string // 配置值来源说明,key为yaml路径
+	owner      map[string]string // 节点域名 => organizations中的组织key
+	dir        string            // Load时配置文件所在目录,相对证书路径基于该目录
 
 	Version                string                            `json:"version,omitempty" yaml:"version"`
 	Client                 Client                            `json:"client,omitempty" yaml:"client,omitempty"`
@@ -247,8 +264,8 @@ type Builder struct {
 	Channels               map[string]ChannelPeer            `json:"channels,omitempty" yaml:"channels,omitempty"`           // key为通道名称
 	Orderers               map[string]Payload                `json:"orderers,omitempty" yaml:"orderers,omitempty"`           // key为组织域名
 	Peers                  map[string]Payload                `json:"peers,omitempty" yaml:"peers,omitempty"`                 // key为组织域名
-	CertificateAuthorities map[string]CertificateAuthorities `json:"certificate_authorities,omitempty" yaml:"certificateAuthorities,omitempty"`
-	EntityMatchers         EntityMatchers                    `json:"entity_matchers,omitempty" yaml:"entityMatchers,omitempty"`
+	CertificateAuthorities map[string]CertificateAuthorities `json:"certificateAuthorities,omitempty" yaml:"certificateAuthorities,omitempty"`
+	EntityMatchers         EntityMatchers                    `json:"entityMatchers,omitempty" yaml:"entityMatchers,omitempty"`
 	Operations             Operations                        `json:"operations,omitempty" yaml:"operations,omitempty"`
 	Metrics                Metrics                           `json:"metrics,omitempty" yaml:"metrics,omitempty"`
 }
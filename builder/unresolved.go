@@ -0,0 +1,87 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chaunsin/fgc/parse/host"
+)
+
+// Unresolved 未能解析出真实地址的服务,生成的配置中会以占位符形式保留
+type Unresolved struct {
+	Section string `json:"section"` // 所在配置块 orderers peers entityMatchers
+	Domain  string `json:"domain"`  // 服务域名
+	Field   string `json:"field"`   // 字段名称
+	Value   string `json:"value"`   // 写入配置文件中的值
+}
+
+func (u Unresolved) String() string {
+	return fmt.Sprintf("%s.%s.%s=%s", u.Section, u.Domain, u.Field, u.Value)
+}
+
+// UnresolvedError 严格模式下存在未解析的服务地址时返回
+type UnresolvedError struct {
+	Endpoints []Unresolved
+}
+
+func (e *UnresolvedError) Error() string {
+	var list = make([]string, 0, len(e.Endpoints))
+	for _, u := range e.Endpoints {
+		list = append(list, u.String())
+	}
+	return fmt.Sprintf("%d unresolved endpoint(s): %s", len(e.Endpoints), strings.Join(list, ", "))
+}
+
+// Report 未解析服务地址汇总,用于CI等场景判断生成的配置是否完整
+type Report struct {
+	Count      int             `json:"count"`
+	Unresolved []Unresolved    `json:"unresolved"`
+	Conflicts  []host.Conflict `json:"conflicts,omitempty"` // 多台主机发现了同一个节点
+}
+
+// hasPlaceholder 判断值中是否包含未替换的占位符
+func hasPlaceholder(value string) bool {
+	return strings.Contains(value, host.PlaceholderIP) || strings.Contains(value, host.PlaceholderPort)
+}
+
+// unresolve 记录未解析的服务地址
+func (b *Builder) unresolve(section, domain, field, value string) {
+	b.unresolved = append(b.unresolved, Unresolved{
+		Section: section,
+		Domain:  domain,
+		Field:   field,
+		Value:   value,
+	})
+}
+
+// Unresolved 返回构建过程中未解析出的服务地址列表,按配置块、域名、字段排序
+func (b *Builder) Unresolved() []Unresolved {
+	var list = make([]Unresolved, len(b.unresolved))
+	copy(list, b.unresolved)
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Section != list[j].Section {
+			return list[i].Section < list[j].Section
+		}
+		if list[i].Domain != list[j].Domain {
+			return list[i].Domain < list[j].Domain
+		}
+		return list[i].Field < list[j].Field
+	})
+	return list
+}
+
+// Conflicts 多主机发现时被多台主机同时发现的节点
+func (b *Builder) Conflicts() []host.Conflict {
+	if c, ok := b.host.(interface{ Conflicts() []host.Conflict }); ok {
+		return c.Conflicts()
+	}
+	return nil
+}
+
+// Report 生成json格式的未解析服务地址汇总
+func (b *Builder) Report() ([]byte, error) {
+	list := b.Unresolved()
+	return json.MarshalIndent(Report{Count: len(list), Unresolved: list, Conflicts: b.Conflicts()}, "", "  ")
+}
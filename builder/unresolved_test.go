@@ -0,0 +1,99 @@
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/chaunsin/fgc/parse/host"
+)
+
+// 只发现了peer0.org1以及orderer.example.com
+var partialHost = testHost{
+	"peer0.org1.example.com": "0.0.0.0:7051",
+	"orderer.example.com":    "0.0.0.0:7050",
+}
+
+var missing = []Unresolved{
+	{Section: "entityMatchers.orderer", Domain: "orderer0.ord2.com", Field: "urlSubstitutionExp", Value: "grpcs://" + host.PlaceholderIP + ":" + host.PlaceholderPort},
+	{Section: "entityMatchers.peer", Domain: "peer0.org2.example.com", Field: "urlSubstitutionExp", Value: "grpcs://" + host.PlaceholderIP + ":" + host.PlaceholderPort},
+	{Section: "entityMatchers.peer", Domain: "peer1.org1.example.com", Field: "urlSubstitutionExp", Value: "grpcs://" + host.PlaceholderIP + ":" + host.PlaceholderPort},
+	{Section: "orderers", Domain: "orderer0.ord2.com", Field: "url", Value: "orderer0.ord2.com:" + host.PlaceholderPort},
+	{Section: "peers", Domain: "peer0.org2.example.com", Field: "url", Value: "peer0.org2.example.com:" + host.PlaceholderPort},
+	{Section: "peers", Domain: "peer1.org1.example.com", Field: "url", Value: "peer1.org1.example.com:" + host.PlaceholderPort},
+}
+
+func TestStrictUnresolved(t *testing.T) {
+	b := testBuilder(Options{Strict: true}, partialHost)
+	err := b.Build(testCrypto())
+	var ue *UnresolvedError
+	if !errors.As(err, &ue) {
+		t.Fatalf("Build: got %v want *UnresolvedError", err)
+	}
+	if !reflect.DeepEqual(ue.Endpoints, missing) {
+		t.Fatalf("Endpoints:\ngot  %v\nwant %v", ue.Endpoints, missing)
+	}
+
+	// 全部解析时严格模式不返回错误
+	all := testHost{}
+	for _, d := range []string{"peer0.org1.example.com", "peer1.org1.example.com", "peer0.org2.example.com", "orderer.example.com", "orderer0.ord2.com"} {
+		all[d] = "0.0.0.0:7051"
+	}
+	if err := testBuilder(Options{Strict: true}, all).Build(testCrypto()); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+}
+
+func TestUnresolved(t *testing.T) {
+	b := testBuilder(Options{}, partialHost)
+	if err := b.Build(testCrypto()); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if got := b.Unresolved(); !reflect.DeepEqual(got, missing) {
+		t.Fatalf("Unresolved:\ngot  %v\nwant %v", got, missing)
+	}
+	if got := b.Peers["peer1.org1.example.com"].Url; !hasPlaceholder(got) {
+		t.Fatalf("peers url: %s", got)
+	}
+	if got := b.Peers["peer0.org1.example.com"].Url; hasPlaceholder(got) {
+		t.Fatalf("peers url: %s", got)
+	}
+
+	data, err := b.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report map[string]json.RawMessage
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := report["conflicts"]; ok {
+		t.Fatalf("report: unexpected conflicts %s", data)
+	}
+	var count int
+	var list []map[string]string
+	if err := json.Unmarshal(report["count"], &count); err != nil || count != len(missing) {
+		t.Fatalf("report count: %d %v", count, err)
+	}
+	if err := json.Unmarshal(report["unresolved"], &list); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"section": "orderers", "domain": "orderer0.ord2.com", "field": "url", "value": "orderer0.ord2.com:" + host.PlaceholderPort}
+	if len(list) != len(missing) || !reflect.DeepEqual(list[3], want) {
+		t.Fatalf("report unresolved: %s", report["unresolved"])
+	}
+}
+
+func TestHasPlaceholder(t *testing.T) {
+	for value, want := range map[string]bool{
+		"peer0.org1.example.com:7051":                    false,
+		"peer0.org1.example.com:" + host.PlaceholderPort: true,
+		host.PlaceholderIP + ":7051":                     true,
+		"":                                               false,
+	} {
+		if got := hasPlaceholder(value); got != want {
+			t.Fatalf("hasPlaceholder(%q): got %v want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package builder
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/chaunsin/fgc/parse"
+)
+
+const allUsers = "all"
+
+// userRule --user中的单项 Admin、all 或者 org1=User1
+type userRule struct {
+	org  string // 为空表示所有组织
+	user string // 用户名或者all
+}
+
+// parseUsers 解析--user参数
+func parseUsers(raw []string) ([]userRule, error) {
+	var list = make([]userRule, 0, len(raw))
+	for _, r := range raw {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		var rule userRule
+		if kv := strings.SplitN(r, "=", 2); len(kv) == 2 {
+			rule.org, rule.user = strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+			if rule.org == "" || rule.user == "" {
+				return nil, fmt.Errorf("invalid user %q, expect org=user", r)
+			}
+		} else {
+			rule.user = r
+		}
+		list = append(list, rule)
+	}
+	return list, nil
+}
+
+// selectUsers 根据--user选择组织下需要生成的用户,按用户域名排序。
+// 组织有单独指定的用户时只使用单独指定的用户,指定的用户不存在时报错;
+// 否则使用未指定组织的用户,用户在该组织中不存在时忽略
+func (b *Builder) selectUsers(name parse.OrgName, org *parse.Org) ([]parse.UserDomain, error) {
+	rules, err := parseUsers(b.opts.Users)
+	if err != nil {
+		return nil, err
+	}
+
+	var own, global []string
+	for _, r := range rules {
+		switch {
+		case r.org == "":
+			global = append(global, r.user)
+		case b.matchOrg(name, r.org):
+			own = append(own, r.user)
+		}
+	}
+	wanted, strict := global, false
+	if len(own) > 0 {
+		wanted, strict = own, true
+	}
+
+	var (
+		seen = make(map[parse.UserDomain]bool)
+		resp []parse.UserDomain
+	)
+	for _, u := range wanted {
+		var found bool
+		for domain := range org.Users {
+			if u == allUsers || domain.UserName() == u {
+				found = true
+				if !seen[domain] {
+					seen[domain] = true
+					resp = append(resp, domain)
+				}
+			}
+		}
+		if found {
+			continue
+		}
+		if strict {
+			return nil, fmt.Errorf("user %s not found in %s", u, name)
+		}
+		log.Printf("[users] user %s not found in %s\n", u, name)
+	}
+	sort.Slice(resp, func(i, j int) bool { return resp[i] < resp[j] })
+	return resp, nil
+}
+
+// validUsers 检查--user中单独指定的组织是否存在
+func (b *Builder) validUsers(cc *parse.CryptoConfig) error {
+	rules, err := parseUsers(b.opts.Users)
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		if r.org == "" {
+			continue
+		}
+		var found bool
+		for name := range cc.Orgs {
+			found = found || b.matchOrg(name, r.org)
+		}
+		for name := range cc.Order {
+			found = found || b.matchOrg(name, r.org)
+		}
+		if !found {
+			return fmt.Errorf("user %s=%s: organization not found", r.org, r.user)
+		}
+	}
+	return nil
+}
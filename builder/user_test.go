@@ -0,0 +1,103 @@
+package builder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chaunsin/fgc/parse"
+)
+
+func TestParseUsers(t *testing.T) {
+	for _, c := range []struct {
+		raw  []string
+		want []userRule
+		err  bool
+	}{
+		{raw: nil, want: []userRule{}},
+		{raw: []string{"Admin", " ", "all"}, want: []userRule{{user: "Admin"}, {user: "all"}}},
+		{raw: []string{"org1 = User1", "Org2MSP=all"}, want: []userRule{{org: "org1", user: "User1"}, {org: "Org2MSP", user: "all"}}},
+		{raw: []string{"org1="}, err: true},
+		{raw: []string{"=Admin"}, err: true},
+	} {
+		got, err := parseUsers(c.raw)
+		if c.err {
+			if err == nil {
+				t.Fatalf("parseUsers(%q): expected error", c.raw)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("parseUsers(%q): got %v %v want %v", c.raw, got, err, c.want)
+		}
+	}
+}
+
+func TestSelectUsers(t *testing.T) {
+	cc := testCrypto()
+	for _, c := range []struct {
+		users []string
+		org   parse.OrgName
+		want  []parse.UserDomain
+		err   string
+	}{
+		{users: nil, org: "org1.example.com", want: nil},
+		{users: []string{"Admin"}, org: "org1.example.com", want: []parse.UserDomain{"Admin@org1.example.com"}},
+		{users: []string{"all"}, org: "org2.example.com", want: []parse.UserDomain{"Admin@org2.example.com", "User1@org2.example.com"}},
+		{users: []string{"User1", "Admin", "User1"}, org: "org1.example.com", want: []parse.UserDomain{"Admin@org1.example.com", "User1@org1.example.com"}},
+		// 未指定组织的用户不存在时忽略
+		{users: []string{"User9", "Admin"}, org: "org1.example.com", want: []parse.UserDomain{"Admin@org1.example.com"}},
+		// 组织单独指定的用户优先,支持组织简称以及mspid
+		{users: []string{"Admin", "org1=User1"}, org: "org1.example.com", want: []parse.UserDomain{"User1@org1.example.com"}},
+		{users: []string{"Admin", "org1=User1"}, org: "org2.example.com", want: []parse.UserDomain{"Admin@org2.example.com"}},
+		{users: []string{"Org2MSP=all"}, org: "org2.example.com", want: []parse.UserDomain{"Admin@org2.example.com", "User1@org2.example.com"}},
+		{users: []string{"example.com=Admin"}, org: "example.com", want: []parse.UserDomain{"Admin@example.com"}},
+		// 组织单独指定的用户不存在时报错
+		{users: []string{"org1=User9"}, org: "org1.example.com", err: "user User9 not found in org1.example.com"},
+		{users: []string{"org1="}, org: "org1.example.com", err: "invalid user"},
+	} {
+		b := testBuilder(Options{Users: c.users}, testHost{})
+		org, ok := cc.Orgs[c.org]
+		if !ok {
+			org = cc.Order[c.org]
+		}
+		got, err := b.selectUsers(c.org, org)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("selectUsers(%q, %s): got %v want %q", c.users, c.org, err, c.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("selectUsers(%q, %s): got %v %v want %v", c.users, c.org, got, err, c.want)
+		}
+	}
+}
+
+func TestValidUsers(t *testing.T) {
+	cc := testCrypto()
+	for _, c := range []struct {
+		users []string
+		err   string
+	}{
+		{users: nil},
+		{users: []string{"all"}},
+		{users: []string{"User9"}},
+		{users: []string{"org1=Admin", "Org2MSP=User1", "ord2.com=Admin"}},
+		{users: []string{"org3=Admin"}, err: "user org3=Admin: organization not found"},
+		{users: []string{"=Admin"}, err: "invalid user"},
+	} {
+		err := testBuilder(Options{Users: c.users}, testHost{}).validUsers(cc)
+		if c.err == "" && err != nil {
+			t.Fatalf("validUsers(%q): %v", c.users, err)
+		}
+		if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Fatalf("validUsers(%q): got %v want %q", c.users, err, c.err)
+		}
+	}
+
+	// Build时校验用户,组织不存在时返回错误
+	if err := testBuilder(Options{Users: []string{"org3=Admin"}}, partialHost).Build(cc); err == nil {
+		t.Fatal("Build: expected error for unknown organization")
+	}
+}
@@ -1,20 +1,37 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/chaunsin/fgc/builder"
+	"github.com/chaunsin/fgc/config"
+	"github.com/chaunsin/fgc/parse"
 	"github.com/chaunsin/fgc/parse/host"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
 type RootOpts struct {
-	Debug   bool   // 是否开启命令行debug模式
-	Input   string // 加载证书路径
-	Output  string // 生成文件路径
-	Stdout  bool   // 生成内容是否打印到标准数据中
-	Service string // 生成链接服务的配置类型 normal:传统方式(默认) gateway:网关方式
+	Project string   // fgc项目配置文件路径(--config)
+	Debug   bool     // 是否开启命令行debug模式
+	Input   string   // 加载证书路径
+	Output  string   // 生成文件路径
+	Stdout  bool     // 生成内容是否打印到标准数据中
+	Service string   // 生成链接服务的配置类型 normal:传统方式(默认) gateway:网关方式
+	Report  string   // 未解析服务地址汇总输出路径,为空时输出到标准错误
+	Target  []string // 多台远程主机 [user@]host[:port][=scope1,scope2]
+	Merge   bool     // 合并到已有配置中,保留手工修改的字段
+	Bundle  bool     // 生成包含引用证书的独立目录,证书路径相对于配置文件
+	Tarball string   // 将--bundle生成的目录打包为tar.gz
+	Kube    bool     // 生成kubernetes ConfigMap以及Secret清单
+	K8s     builder.K8s
 	builder.Options
 	host.Config
 }
@@ -31,34 +48,174 @@ func New() *Cmd {
 			Example: "fgc golang -i ./crypto-config",
 		},
 	}
+	c.root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return c.loadConfig(cmd.Flags())
+	}
 	c.addFlags()
 	c.Add(newGolangCmd(c))
 	c.Add(newNodeJSCmd(c))
 	c.Add(newJavaCmd(c))
+	c.Add(newDiffCmd(c))
 	return c
 }
 
 func (c *Cmd) addFlags() {
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Project, "config", "", "fgc project file, flags override the file and FGC_* env vars override both, eg: ./fgc.yaml")
 	c.root.PersistentFlags().BoolVar(&c.RootOpts.Debug, "debug", false, "")
 	c.root.PersistentFlags().StringVarP(&c.RootOpts.Input, "input", "i", defaultString("FABRIC_CFG_PATH", "./crypto-config"), "gen [command] -i ./crypto-config")
 	c.root.PersistentFlags().StringVarP(&c.RootOpts.Output, "output", "p", "./", "Generate file directory location")
 	c.root.PersistentFlags().BoolVar(&c.RootOpts.Stdout, "stdout", false, "")
-	c.root.PersistentFlags().StringVarP(&c.RootOpts.FileType, "type", "t", "yaml", "Generated file type")
+	c.root.PersistentFlags().BoolVar(&c.RootOpts.Merge, "merge", false, "Update the existing config, refreshing certs, endpoints, peers and entity matchers while keeping manual edits, the old file is kept as .bak")
+	c.root.PersistentFlags().BoolVar(&c.RootOpts.Bundle, "bundle", false, "Write a self-contained directory to --output, copying only the referenced certs and keys with paths relative to the config file")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Tarball, "tar", "", "Also pack the --bundle directory into this tar.gz file, eg: ./fabric.tar.gz")
+	c.root.PersistentFlags().BoolVar(&c.RootOpts.Kube, "k8s", false, "Write kubernetes ConfigMap and Secret manifests to --output instead of the config file, requires --pem")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.K8s.Name, "k8s-name", "fabric", "Name prefix of the kubernetes resources")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.K8s.Namespace, "k8s-namespace", "", "Namespace of the kubernetes resources")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.K8s.MountPath, "k8s-mount", "/etc/hyperledger/fabric", "Directory the config and certs are mounted at in the container")
+	c.root.PersistentFlags().BoolVar(&c.RootOpts.K8s.Kustomize, "kustomize", false, "Also write kustomization.yaml so the manifests can be used as a kustomize base")
+	c.root.PersistentFlags().StringVarP(&c.RootOpts.FileType, "type", "t", "yaml", "Generated file type: yaml|json|env|properties|toml")
+	c.root.PersistentFlags().BoolVar(&c.RootOpts.Strict, "strict", false, "Fail the build when any endpoint can not be resolved instead of writing ${IP}/${PORT} placeholders")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Template, "template", "", "Render with a built-in template (go|java|node) or a text/template file, eg: ./config.yaml.tmpl")
+	c.root.PersistentFlags().BoolVar(&c.RootOpts.Comments, "comments", false, "Add comments describing each section and where the values came from to the generated yaml")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Report, "report", "", "Write the JSON summary of unresolved endpoints to this file, default stderr")
 	c.root.PersistentFlags().StringVarP(&c.RootOpts.Service, "service", "s", "normal", "")
 	c.root.PersistentFlags().BoolVar(&c.RootOpts.Pem, "pem", false, "")
 	c.root.PersistentFlags().BoolVar(&c.RootOpts.DoubleTls, "tls", false, "Whether to enable bidirectional TLS authentication. The default value is unidirectional")
-	c.root.PersistentFlags().BoolVar(&c.RootOpts.CA, "ca", false, "")
+	c.root.PersistentFlags().StringSliceVar(&c.RootOpts.PeerClientTLS, "peer-client-tls", nil, "Per endpoint mutual TLS client cert, pattern=org uses the first selected user of org, requires --tls and the java template or a custom --template (fabric-sdk-go ignores it)")
+	c.root.PersistentFlags().BoolVar(&c.RootOpts.NoTLS, "no-tls", false, "The network runs without TLS, use grpc:// urls and allow-insecure and omit tlsCACerts, by default detected from container env")
+	c.root.PersistentFlags().StringArrayVar(&c.RootOpts.EndpointTLS, "endpoint-tls", nil, "Per endpoint TLS, repeatable: pattern=on|off, pattern is a domain glob or org domain eg: peer0.org1.example.com=off")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Matchers, "matchers", "node", "Entity matchers: node (one per node with discovered address)|host (mappedHost only)|org (one wildcard per org)")
+	c.root.PersistentFlags().BoolVar(&c.RootOpts.CA, "ca", false, "Generate certificateAuthorities (not implemented yet: no CA entries and therefore no CA entity matchers are generated)")
 	c.root.PersistentFlags().BoolVar(&c.RootOpts.Metrics, "metrics", false, "")
 	c.root.PersistentFlags().BoolVar(&c.RootOpts.Operations, "operations", false, "")
 	c.root.PersistentFlags().StringVarP(&c.RootOpts.OrgName, "org", "o", "org1", "Organization name")
 	c.root.PersistentFlags().StringVarP(&c.RootOpts.OrderName, "order", "O", "order", "Orderer name")
-	c.root.PersistentFlags().StringVarP(&c.RootOpts.ChannelName, "channel", "c", "mychannel", "The name of the channel used")
-	c.root.PersistentFlags().StringVarP(&c.RootOpts.User, "user", "u", "Admin", "The user name used")
+	c.root.PersistentFlags().StringSliceVarP(&c.RootOpts.Channels, "channel", "c", nil, "Channel names, name or name:profile of configtx.yaml, default mychannel")
+	c.root.PersistentFlags().StringArrayVar(&c.RootOpts.ChannelOrgs, "channel-orgs", nil, "Channel member organizations or orderers, repeatable: name=org1.example.com,org2.example.com,example.com")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.ChannelFile, "channel-config", "", "YAML file mapping channel name to member orgs and orderers")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.ConfigTx, "configtx", "", "configtx.yaml used to derive channel members from profiles")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Policy, "policy", "default", "Channel policies preset: default|dev|prod-ha")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.PolicyFile, "policy-config", "", "YAML file overriding fields of the channel policies preset")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Grpc, "grpc", "default", "gRPC options preset: default|lan|wan")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.GrpcFile, "grpc-config", "", "YAML file overriding gRPC options for all endpoints (default), per role (peer|orderer|ca) and per domain pattern (endpoints)")
+	c.root.PersistentFlags().StringArrayVar(&c.RootOpts.GrpcOpts, "grpc-opt", nil, "gRPC option, repeatable: [role|pattern:]key=value eg: keep-alive-time=30s, peer:fail-fast=true, *.org2.example.com:ssl-target-name-override=")
+	c.root.PersistentFlags().StringArrayVar(&c.RootOpts.PeerRoles, "peer-role", nil, "Peer channel roles, repeatable: pattern=-endorsingPeer,-eventSource, pattern is a domain glob or org, roles also accept all|none|committer")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.LogLevel, "log-level", "info", "SDK log level: debug|info|warning|error|critical")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.StorePath, "store", "./data/keystore", "Client credential store path")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.CryptoStorePath, "crypto-store", "./data/msp", "Client crypto store path")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Provider, "bccsp", "SW", "BCCSP provider: SW|PKCS11")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.HashAlgorithm, "hash", "SHA2", "BCCSP hash algorithm: SHA2|SHA3")
+	c.root.PersistentFlags().IntVar(&c.RootOpts.SecurityLevel, "security-level", 256, "BCCSP security level: 256|384")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.PKCS11Library, "pkcs11-lib", "", "PKCS11 library paths separated by comma, required by --bccsp PKCS11")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.PKCS11Label, "pkcs11-label", "", "PKCS11 token label, required by --bccsp PKCS11")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.PKCS11Pin, "pkcs11-pin", "", "PKCS11 pin written to the config (0600), also read from FGC_PKCS11_PIN. empty writes ${FABRIC_SDK_CLIENT_BCCSP_SECURITY_PIN} to be overridden by the sdk env")
+	c.root.PersistentFlags().StringSliceVarP(&c.RootOpts.Users, "user", "u", []string{"Admin"}, "The user names used, all for every user, org=user for a single organization eg: Admin,org2=User1")
 	c.root.PersistentFlags().StringVarP(&c.RootOpts.Mode, "mode", "m", "local", "local,sftp,ftp")
 	c.root.PersistentFlags().StringVarP(&c.RootOpts.Addr, "host", "H", "", "Service ip address or domain name")
 	c.root.PersistentFlags().StringVarP(&c.RootOpts.Username, "username", "U", "root", "")
 	c.root.PersistentFlags().StringVarP(&c.RootOpts.Password, "password", "P", "", "")
-	c.root.PersistentFlags().StringVarP(&c.RootOpts.PrivateKey, "key", "k", ".ssh/key.pem", "")
+	c.root.PersistentFlags().StringVarP(&c.RootOpts.PrivateKey, "key", "k", "", "SSH private key file")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Passphrase, "passphrase", "", "Passphrase of the encrypted private key, also read from FGC_PASSPHRASE, prompted when empty")
+	c.root.PersistentFlags().BoolVar(&c.RootOpts.Agent, "agent", false, "Authenticate with ssh-agent through SSH_AUTH_SOCK")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.KnownHosts, "known-hosts", "", "known_hosts file used to verify the host key, default ~/.ssh/known_hosts")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Fingerprint, "fingerprint", "", "Pinned SHA256 host key fingerprint, eg: SHA256:xxx")
+	c.root.PersistentFlags().BoolVar(&c.RootOpts.Insecure, "insecure-host-key", false, "Skip host key verification, only for testing")
+	c.root.PersistentFlags().StringSliceVarP(&c.RootOpts.Jump, "jump", "J", nil, "Jump hosts [user@]host[:port], connected in order")
+	c.root.PersistentFlags().StringArrayVar(&c.RootOpts.Target, "target", nil, "Remote host to discover, repeatable: [user@]host[:port][=org or node domain,...]")
+	c.root.PersistentFlags().IntVar(&c.RootOpts.Workers, "workers", 4, "Number of remote hosts discovered concurrently")
+	c.root.PersistentFlags().StringVar(&c.RootOpts.Runtime, "runtime", "docker", "Container runtime used for discovery: docker|podman|nerdctl")
+	c.RootOpts.Prompt = prompt
+}
+
+// loadConfig 加载--config项目配置文件中未通过命令行指定的参数,再使用FGC_*环境变量覆盖,
+// 优先级 环境变量 > 命令行参数 > 配置文件 > 参数默认值
+func (c *Cmd) loadConfig(flags *pflag.FlagSet) error {
+	// 项目配置文件路径本身也可以通过FGC_CONFIG指定
+	if err := setEnv(flags.Lookup("config")); err != nil {
+		return err
+	}
+	if c.RootOpts.Project != "" {
+		cfg, err := config.Load(c.RootOpts.Project)
+		if err != nil {
+			return fmt.Errorf("config:%w", err)
+		}
+		values := cfg.Flags()
+		for _, name := range sortedFlags(values) {
+			f := flags.Lookup(name)
+			if f == nil {
+				return fmt.Errorf("config: flag --%s not supported by %s", name, c.root.Name())
+			}
+			if f.Changed {
+				continue
+			}
+			if err := setFlag(f, values[name]); err != nil {
+				return fmt.Errorf("config: invalid %s: %w", name, err)
+			}
+		}
+	}
+
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if err == nil {
+			err = setEnv(f)
+		}
+	})
+	return err
+}
+
+// setEnv 使用参数对应的FGC_*环境变量覆盖参数值,切片参数使用,分隔
+func setEnv(f *pflag.Flag) error {
+	v, ok := os.LookupEnv(config.EnvName(f.Name))
+	if !ok {
+		return nil
+	}
+	values := []string{v}
+	if f.Value.Type() == "stringSlice" {
+		values = strings.Split(v, ",")
+	}
+	if err := setFlag(f, values); err != nil {
+		return fmt.Errorf("env %s: %w", config.EnvName(f.Name), err)
+	}
+	return nil
+}
+
+// setFlag 设置参数值,切片参数替换已有的值而不是追加
+func setFlag(f *pflag.Flag, values []string) error {
+	if s, ok := f.Value.(pflag.SliceValue); ok {
+		if err := s.Replace(values); err != nil {
+			return err
+		}
+	} else {
+		for _, v := range values {
+			if err := f.Value.Set(v); err != nil {
+				return err
+			}
+		}
+	}
+	f.Changed = true
+	return nil
+}
+
+func sortedFlags(m map[string][]string) []string {
+	var list = make([]string, 0, len(m))
+	for k := range m {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// hostConfig 合并--target参数生成节点发现配置
+func (o RootOpts) hostConfig() (host.Config, error) {
+	c := o.Config
+	for _, raw := range o.Target {
+		t, err := host.ParseTarget(raw)
+		if err != nil {
+			return host.Config{}, fmt.Errorf("ParseTarget:%w", err)
+		}
+		c.Targets = append(c.Targets, t)
+	}
+	return c, nil
 }
 
 func (c *Cmd) Version(version string) {
@@ -75,6 +232,186 @@ func (c *Cmd) Execute() {
 	}
 }
 
+// build 读取证书目录并构建配置
+func build(opts RootOpts) (*builder.Builder, error) {
+	// 根据模式读取文件
+	cc, err := parse.Open(opts.Input, opts.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+
+	hc, err := opts.hostConfig()
+	if err != nil {
+		return nil, fmt.Errorf("host: %w", err)
+	}
+
+	// 模板对象
+	b, err := builder.New(hc, opts.Options)
+	if err != nil {
+		return nil, fmt.Errorf("new: %w", err)
+	}
+	if err := b.Build(cc); err != nil {
+		return nil, fmt.Errorf("build: %w", err)
+	}
+	return b, nil
+}
+
+// generate 构建配置并写入输出目录
+func generate(opts RootOpts) error {
+	b, err := build(opts)
+	if err != nil {
+		return err
+	}
+	if opts.Bundle {
+		return bundle(b, opts)
+	}
+	if opts.Kube {
+		return kubernetes(b, opts)
+	}
+	if opts.Tarball != "" {
+		return fmt.Errorf("--tar requires --bundle")
+	}
+
+	dir := fmt.Sprintf("%s/config.%s", opts.Output, b.Ext())
+	content, err := b.Content()
+	if err != nil {
+		return fmt.Errorf("serialize:%w", err)
+	}
+
+	// 合并到已有配置,只支持yaml以及json
+	var existing []byte
+	if opts.Merge {
+		if opts.Template != "" || (b.Ext() != "yaml" && b.Ext() != "json") {
+			return fmt.Errorf("--merge only supports yaml or json without --template")
+		}
+		if existing, err = os.ReadFile(dir); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("ReadFile:%w", err)
+		}
+	}
+	if len(existing) > 0 {
+		if content, err = b.Merge(existing); err != nil {
+			return fmt.Errorf("merge:%w", err)
+		}
+	}
+
+	if opts.Stdout {
+		fmt.Fprintf(os.Stdout, "##### CONTEXT #####\n%s\n", content)
+	}
+
+	if err := os.MkdirAll(opts.Output, 0755); err != nil {
+		return fmt.Errorf("output path %s invalid", opts.Output)
+	}
+	if len(existing) > 0 {
+		// 备份保持原文件权限,原文件可能包含私钥
+		info, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("Stat:%w", err)
+		}
+		if err := builder.WriteFile(dir+".bak", existing, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("backup:%w", err)
+		}
+	}
+	if err := builder.WriteFile(dir, content, b.ConfigMode()); err != nil {
+		return fmt.Errorf("WriteFile:%s", err)
+	}
+	if err := report(b, opts.Report); err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+	return nil
+}
+
+// bundle 生成包含引用证书的独立目录,可选打包为tar.gz
+func bundle(b *builder.Builder, opts RootOpts) error {
+	if opts.Merge {
+		return fmt.Errorf("--bundle can not be used with --merge")
+	}
+	if err := b.Bundle(opts.Output); err != nil {
+		return fmt.Errorf("bundle:%w", err)
+	}
+	if opts.Stdout {
+		fmt.Fprintf(os.Stdout, "##### CONTEXT #####\n")
+		if _, err := b.WriteTo(os.Stdout); err != nil {
+			return fmt.Errorf("serialize:%w", err)
+		}
+		fmt.Fprintln(os.Stdout)
+	}
+	if opts.Tarball != "" {
+		// 先在内存中打包,避免tar文件位于打包目录中
+		var buf bytes.Buffer
+		if err := b.Tarball(opts.Output, &buf); err != nil {
+			return fmt.Errorf("tarball:%w", err)
+		}
+		// 包含私钥
+		if err := builder.WriteFile(opts.Tarball, buf.Bytes(), 0600); err != nil {
+			return fmt.Errorf("WriteFile:%w", err)
+		}
+	}
+	if err := report(b, opts.Report); err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+	return nil
+}
+
+// kubernetes 生成kubernetes清单,包含私钥的Secret权限为0600
+func kubernetes(b *builder.Builder, opts RootOpts) error {
+	if opts.Merge {
+		return fmt.Errorf("--k8s can not be used with --merge")
+	}
+	files, err := b.Kubernetes(opts.K8s)
+	if err != nil {
+		return fmt.Errorf("kubernetes:%w", err)
+	}
+	if err := os.MkdirAll(opts.Output, 0755); err != nil {
+		return fmt.Errorf("output path %s invalid", opts.Output)
+	}
+	for _, f := range files {
+		if opts.Stdout {
+			fmt.Fprintf(os.Stdout, "##### %s #####\n%s\n", f.Name, f.Data)
+		}
+		var perm os.FileMode = 0644
+		if f.Secret {
+			perm = 0600
+		}
+		if err := builder.WriteFile(filepath.Join(opts.Output, f.Name), f.Data, perm); err != nil {
+			return fmt.Errorf("WriteFile:%w", err)
+		}
+	}
+	if err := report(b, opts.Report); err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+	return nil
+}
+
+// report 非严格模式下输出配置中遗留占位符以及多主机发现冲突的汇总信息
+func report(b *builder.Builder, path string) error {
+	if len(b.Unresolved()) == 0 && len(b.Conflicts()) == 0 {
+		return nil
+	}
+	data, err := b.Report()
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	if path == "" {
+		fmt.Fprintf(os.Stderr, "%s\n", data)
+		return nil
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("WriteFile:%w", err)
+	}
+	return nil
+}
+
+// prompt 从终端读取私钥密码,不回显
+func prompt(msg string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("stdin is not a terminal")
+	}
+	fmt.Fprint(os.Stderr, msg)
+	defer fmt.Fprintln(os.Stderr)
+	return term.ReadPassword(fd)
+}
+
 func defaultString(env, value string) string {
 	v := os.Getenv(env)
 	if v == "" {
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/chaunsin/fgc/builder"
+
+	"github.com/spf13/cobra"
+)
+
+type diffCmd struct {
+	cli *Cmd
+	cmd *cobra.Command
+
+	against  string // 已有的配置文件
+	json     bool   // json格式输出差异
+	exitCode bool   // 存在差异时返回错误
+}
+
+func newDiffCmd(c *Cmd) *cobra.Command {
+	s := &diffCmd{
+		cli: c,
+	}
+	s.cmd = &cobra.Command{
+		Use:     "diff",
+		Short:   "Regenerate from crypto-config and report semantic differences against an existing fabric-sdk-go config",
+		Example: "fgc diff -i ./crypto-config --against ./config.yaml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return s.diff()
+		},
+	}
+	s.addFlags()
+	return s.cmd
+}
+
+func (s *diffCmd) addFlags() {
+	s.cmd.Flags().StringVar(&s.against, "against", "./config.yaml", "Existing fabric-sdk-go config file, yaml or json")
+	s.cmd.Flags().BoolVar(&s.json, "json", false, "Print differences as json")
+	s.cmd.Flags().BoolVar(&s.exitCode, "exit-code", false, "Exit with an error when there are differences")
+}
+
+func (s *diffCmd) diff() error {
+	opts := s.cli.RootOpts
+	opts.Language = "golang"
+
+	old, err := builder.Load(s.against)
+	if err != nil {
+		return fmt.Errorf("load: %w", err)
+	}
+	b, err := build(opts)
+	if err != nil {
+		return err
+	}
+
+	changes := builder.Diff(old, b)
+	if s.json {
+		data, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+	} else {
+		for _, c := range changes {
+			fmt.Fprintln(os.Stdout, c)
+		}
+	}
+
+	if s.exitCode && len(changes) > 0 {
+		return errors.New("config differs")
+	}
+	return nil
+}
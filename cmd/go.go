@@ -1,12 +1,6 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-
-	"github.com/chaunsin/fgc/builder"
-	"github.com/chaunsin/fgc/parse"
-
 	"github.com/spf13/cobra"
 )
 
@@ -39,33 +33,5 @@ func (s *golangCmd) addFlags() {
 func (s *golangCmd) generate() error {
 	opts := s.cli.RootOpts
 	opts.Language = "golang"
-
-	// 根据模式读取文件
-	cc, err := parse.Open(opts.Input, opts.Mode)
-	if err != nil {
-		return fmt.Errorf("open: %w", err)
-	}
-
-	// 模板对象
-	b := builder.New(opts.Config, opts.Options)
-	if err := b.Build(cc); err != nil {
-		return fmt.Errorf("build: %w", err)
-	}
-	content, err := b.Content()
-	if err != nil {
-		return fmt.Errorf("serialize:%w", err)
-	}
-
-	if opts.Stdout {
-		fmt.Fprintf(os.Stdout, "##### CONTEXT #####\n%s\n", content)
-	}
-
-	if err := os.MkdirAll(opts.Output, os.ModePerm); err != nil {
-		return fmt.Errorf("output path %s invalid", opts.Output)
-	}
-	dir := fmt.Sprintf("%s/config.%s", opts.Output, opts.FileType)
-	if err := os.WriteFile(dir, content, os.ModePerm); err != nil {
-		return fmt.Errorf("WriteFile:%s", err)
-	}
-	return nil
+	return generate(opts)
 }
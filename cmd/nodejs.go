@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"errors"
-
 	"github.com/spf13/cobra"
 )
 
@@ -38,5 +36,10 @@ func (s *nodejsCmd) addFlags() {
 }
 
 func (s *nodejsCmd) handler() error {
-	return errors.New("该命令暂不支持")
+	opts := s.cli.RootOpts
+	opts.Language = "nodejs"
+	if opts.Template == "" {
+		opts.Template = "node"
+	}
+	return generate(opts)
 }
@@ -1,13 +1,265 @@
 package config
 
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix 环境变量前缀,FGC_加大写的参数名并将-替换为_ eg: --grpc-opt => FGC_GRPC_OPT
+const EnvPrefix = "FGC_"
+
+// Config fgc项目配置文件(--config fgc.yaml),描述证书来源、节点发现、组织、用户、通道、输出以及覆盖规则,
+// 字段与命令行参数一一对应,未填写的字段使用参数默认值。优先级: 环境变量 > 命令行参数 > 配置文件
 type Config struct {
-	Url string `json:"url"`
-	Dir string `json:"dir"`
+	Input     Input              `yaml:"input"`
+	Discovery Discovery          `yaml:"discovery"`
+	Org       string             `yaml:"org"`      // --org 客户端所属组织
+	Order     string             `yaml:"order"`    // --order 排序节点名称
+	Users     []string           `yaml:"users"`    // --user 用户名,org=user单独指定组织的用户
+	Channels  map[string]Channel `yaml:"channels"` // 通道名称 => 通道成员
+	ConfigTx  string             `yaml:"configtx"` // --configtx
+	Output    Output             `yaml:"output"`
+	TLS       TLS                `yaml:"tls"`
+	Client    Client             `yaml:"client"`
+	Overrides Overrides          `yaml:"overrides"`
+	CA        *bool              `yaml:"ca"`         // --ca
+	Metrics   *bool              `yaml:"metrics"`    // --metrics
+	Operation *bool              `yaml:"operations"` // --operations
+}
+
+// Input 证书来源
+type Input struct {
+	Path string `yaml:"path"` // --input crypto-config目录
+	Mode string `yaml:"mode"` // --mode local sftp ftp
+}
+
+// Discovery 节点发现,连接远程主机读取容器信息
+type Discovery struct {
+	Host        string   `yaml:"host"`            // --host
+	Username    string   `yaml:"username"`        // --username
+	Password    string   `yaml:"password"`        // --password,建议使用环境变量FGC_PASSWORD
+	Key         string   `yaml:"key"`             // --key ssh私钥
+	Passphrase  string   `yaml:"passphrase"`      // --passphrase
+	Agent       *bool    `yaml:"agent"`           // --agent
+	KnownHosts  string   `yaml:"knownHosts"`      // --known-hosts
+	Fingerprint string   `yaml:"fingerprint"`     // --fingerprint
+	Insecure    *bool    `yaml:"insecureHostKey"` // --insecure-host-key
+	Jump        []string `yaml:"jump"`            // --jump 跳板机,按顺序连接
+	Targets     []string `yaml:"targets"`         // --target [user@]host[:port][=scope,...]
+	Workers     *int     `yaml:"workers"`         // --workers
+	Runtime     string   `yaml:"runtime"`         // --runtime docker podman nerdctl
+	Strict      *bool    `yaml:"strict"`          // --strict
+	Report      string   `yaml:"report"`          // --report
+}
+
+// Channel 通道成员,Orgs以及Orderers为空时为所有组织
+type Channel struct {
+	Profile  string   `yaml:"profile"`  // configtx.yaml中的profile
+	Orgs     []string `yaml:"orgs"`     // peer组织
+	Orderers []string `yaml:"orderers"` // 排序组织或者排序节点
+}
+
+// Output 输出目标
+type Output struct {
+	Dir      string `yaml:"dir"`      // --output
+	Type     string `yaml:"type"`     // --type yaml json env properties toml
+	Template string `yaml:"template"` // --template
+	Pem      *bool  `yaml:"pem"`      // --pem
+	Stdout   *bool  `yaml:"stdout"`   // --stdout
+	Comments *bool  `yaml:"comments"` // --comments
+	Merge    *bool  `yaml:"merge"`    // --merge
+	Bundle   *bool  `yaml:"bundle"`   // --bundle
+	Tar      string `yaml:"tar"`      // --tar
+	K8s      K8s    `yaml:"k8s"`
+}
+
+// K8s kubernetes清单
+type K8s struct {
+	Enabled   *bool  `yaml:"enabled"`   // --k8s
+	Name      string `yaml:"name"`      // --k8s-name
+	Namespace string `yaml:"namespace"` // --k8s-namespace
+	Mount     string `yaml:"mount"`     // --k8s-mount
+	Kustomize *bool  `yaml:"kustomize"` // --kustomize
+}
+
+// TLS 节点tls以及双向tls
+type TLS struct {
+	Mutual     *bool    `yaml:"mutual"`     // --tls
+	Disabled   *bool    `yaml:"disabled"`   // --no-tls
+	Endpoints  []string `yaml:"endpoints"`  // --endpoint-tls pattern=on|off
+	PeerClient []string `yaml:"peerClient"` // --peer-client-tls pattern=org
+	Matchers   string   `yaml:"matchers"`   // --matchers node host org
+}
+
+// Client 客户端配置
+type Client struct {
+	LogLevel    string `yaml:"logLevel"`    // --log-level
+	Store       string `yaml:"store"`       // --store
+	CryptoStore string `yaml:"cryptoStore"` // --crypto-store
+	BCCSP       BCCSP  `yaml:"bccsp"`
+}
+
+// BCCSP 加密服务
+type BCCSP struct {
+	Provider string `yaml:"provider"` // --bccsp SW PKCS11
+	Hash     string `yaml:"hash"`     // --hash SHA2 SHA3
+	Level    *int   `yaml:"level"`    // --security-level 256 384
+	PKCS11   struct {
+		Library string `yaml:"library"` // --pkcs11-lib
+		Label   string `yaml:"label"`   // --pkcs11-label
+		Pin     string `yaml:"pin"`     // --pkcs11-pin,建议使用环境变量FGC_PKCS11_PIN
+	} `yaml:"pkcs11"`
+}
+
+// Overrides 覆盖生成结果的规则
+type Overrides struct {
+	PeerRoles     []string `yaml:"peerRoles"`     // --peer-role pattern=role
+	Policy        string   `yaml:"policy"`        // --policy default dev prod-ha
+	PolicyConfig  string   `yaml:"policyConfig"`  // --policy-config
+	Grpc          string   `yaml:"grpc"`          // --grpc default lan wan
+	GrpcConfig    string   `yaml:"grpcConfig"`    // --grpc-config
+	GrpcOpts      []string `yaml:"grpcOpts"`      // --grpc-opt [role|pattern:]key=value
+	ChannelConfig string   `yaml:"channelConfig"` // --channel-config
 }
 
-func New(dir string) *Config {
-	return &Config{
-		Url: "0.0.0.0:8080",
-		Dir: dir,
+// Load 读取配置文件,存在未知字段时返回错误
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile:%w", err)
+	}
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("Decode %s:%w", path, err)
+	}
+	return &c, nil
+}
+
+// Flags 转换为命令行参数名 => 参数值,未填写的字段不包含在内,切片参数每个元素一个值
+func (c *Config) Flags() map[string][]string {
+	var (
+		flags = make(map[string][]string)
+		str   = func(name, v string) {
+			if v != "" {
+				flags[name] = []string{v}
+			}
+		}
+		list = func(name string, v []string) {
+			if len(v) > 0 {
+				flags[name] = append([]string{}, v...)
+			}
+		}
+		boolean = func(name string, v *bool) {
+			if v != nil {
+				flags[name] = []string{strconv.FormatBool(*v)}
+			}
+		}
+		integer = func(name string, v *int) {
+			if v != nil {
+				flags[name] = []string{strconv.Itoa(*v)}
+			}
+		}
+	)
+
+	str("input", c.Input.Path)
+	str("mode", c.Input.Mode)
+
+	d := c.Discovery
+	str("host", d.Host)
+	str("username", d.Username)
+	str("password", d.Password)
+	str("key", d.Key)
+	str("passphrase", d.Passphrase)
+	boolean("agent", d.Agent)
+	str("known-hosts", d.KnownHosts)
+	str("fingerprint", d.Fingerprint)
+	boolean("insecure-host-key", d.Insecure)
+	list("jump", d.Jump)
+	list("target", d.Targets)
+	integer("workers", d.Workers)
+	str("runtime", d.Runtime)
+	boolean("strict", d.Strict)
+	str("report", d.Report)
+
+	str("org", c.Org)
+	str("order", c.Order)
+	list("user", c.Users)
+	str("configtx", c.ConfigTx)
+	var names []string
+	for name := range c.Channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ch := c.Channels[name]
+		if ch.Profile != "" {
+			flags["channel"] = append(flags["channel"], name+":"+ch.Profile)
+		} else {
+			flags["channel"] = append(flags["channel"], name)
+		}
+		if members := append(append([]string{}, ch.Orgs...), ch.Orderers...); len(members) > 0 {
+			flags["channel-orgs"] = append(flags["channel-orgs"], name+"="+strings.Join(members, ","))
+		}
 	}
+
+	o := c.Output
+	str("output", o.Dir)
+	str("type", o.Type)
+	str("template", o.Template)
+	boolean("pem", o.Pem)
+	boolean("stdout", o.Stdout)
+	boolean("comments", o.Comments)
+	boolean("merge", o.Merge)
+	boolean("bundle", o.Bundle)
+	str("tar", o.Tar)
+	boolean("k8s", o.K8s.Enabled)
+	str("k8s-name", o.K8s.Name)
+	str("k8s-namespace", o.K8s.Namespace)
+	str("k8s-mount", o.K8s.Mount)
+	boolean("kustomize", o.K8s.Kustomize)
+
+	boolean("tls", c.TLS.Mutual)
+	boolean("no-tls", c.TLS.Disabled)
+	list("endpoint-tls", c.TLS.Endpoints)
+	list("peer-client-tls", c.TLS.PeerClient)
+	str("matchers", c.TLS.Matchers)
+
+	str("log-level", c.Client.LogLevel)
+	str("store", c.Client.Store)
+	str("crypto-store", c.Client.CryptoStore)
+	str("bccsp", c.Client.BCCSP.Provider)
+	str("hash", c.Client.BCCSP.Hash)
+	integer("security-level", c.Client.BCCSP.Level)
+	str("pkcs11-lib", c.Client.BCCSP.PKCS11.Library)
+	str("pkcs11-label", c.Client.BCCSP.PKCS11.Label)
+	str("pkcs11-pin", c.Client.BCCSP.PKCS11.Pin)
+
+	r := c.Overrides
+	list("peer-role", r.PeerRoles)
+	str("policy", r.Policy)
+	str("policy-config", r.PolicyConfig)
+	str("grpc", r.Grpc)
+	str("grpc-config", r.GrpcConfig)
+	list("grpc-opt", r.GrpcOpts)
+	str("channel-config", r.ChannelConfig)
+
+	boolean("ca", c.CA)
+	boolean("metrics", c.Metrics)
+	boolean("operations", c.Operation)
+	return flags
+}
+
+// EnvName 参数对应的环境变量名
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
 }
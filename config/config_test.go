@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "fgc.yaml")
+	data := `input:
+  path: ./crypto-config
+org: org1
+users: [Admin, org2=User1]
+channels:
+  mychannel:
+    profile: TwoOrgsChannel
+  other:
+    orgs: [org1.example.com]
+    orderers: [example.com]
+output:
+  pem: false
+client:
+  bccsp:
+    level: 384
+overrides:
+  grpcOpts: ["peer:fail-fast=true"]
+`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"input":          {"./crypto-config"},
+		"org":            {"org1"},
+		"user":           {"Admin", "org2=User1"},
+		"channel":        {"mychannel:TwoOrgsChannel", "other"},
+		"channel-orgs":   {"other=org1.example.com,example.com"},
+		"pem":            {"false"},
+		"security-level": {"384"},
+		"grpc-opt":       {"peer:fail-fast=true"},
+	}
+	if got := c.Flags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("flags: %v, want %v", got, want)
+	}
+
+	if err := os.WriteFile(file, []byte("output:\n  dirs: ./\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(file); err == nil {
+		t.Error("unknown field: expect error")
+	}
+	if got := EnvName("grpc-opt"); got != "FGC_GRPC_OPT" {
+		t.Errorf("env name: %s", got)
+	}
+}
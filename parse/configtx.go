@@ -0,0 +1,90 @@
+package parse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigTxOrg configtx.yaml中Organizations下的组织
+type ConfigTxOrg struct {
+	Name   string `yaml:"Name"`
+	ID     string `yaml:"ID"` // mspid
+	MSPDir string `yaml:"MSPDir"`
+}
+
+// OrgName 根据MSPDir推断crypto-config中的组织名称
+// eg: ../organizations/peerOrganizations/org1.example.com/msp => org1.example.com
+func (o ConfigTxOrg) OrgName() string {
+	list := strings.Split(filepath.ToSlash(o.MSPDir), "/")
+	for i, v := range list {
+		if (v == peerPath || v == orderPath) && i+1 < len(list) {
+			return list[i+1]
+		}
+	}
+	return ""
+}
+
+// Consenter raft共识节点
+type Consenter struct {
+	Host string `yaml:"Host"`
+	Port int    `yaml:"Port"`
+}
+
+// ConfigTxProfile configtx.yaml中Profiles下的配置
+type ConfigTxProfile struct {
+	Orderer *struct {
+		Addresses     []string      `yaml:"Addresses"`
+		Organizations []ConfigTxOrg `yaml:"Organizations"`
+		EtcdRaft      struct {
+			Consenters []Consenter `yaml:"Consenters"`
+		} `yaml:"EtcdRaft"`
+	} `yaml:"Orderer"`
+	Application *struct {
+		Organizations []ConfigTxOrg `yaml:"Organizations"`
+	} `yaml:"Application"`
+}
+
+// ConfigTx configtx.yaml 只解析生成通道配置需要用到的字段
+type ConfigTx struct {
+	Organizations []ConfigTxOrg              `yaml:"Organizations"`
+	Profiles      map[string]ConfigTxProfile `yaml:"Profiles"`
+}
+
+// MspIds 组织名称到mspid的映射,组织名称根据MSPDir推断
+func (c *ConfigTx) MspIds() map[string]string {
+	var resp = make(map[string]string)
+	add := func(list []ConfigTxOrg) {
+		for _, o := range list {
+			if name := o.OrgName(); name != "" && o.ID != "" {
+				resp[name] = o.ID
+			}
+		}
+	}
+	add(c.Organizations)
+	for _, p := range c.Profiles {
+		if p.Orderer != nil {
+			add(p.Orderer.Organizations)
+		}
+		if p.Application != nil {
+			add(p.Application.Organizations)
+		}
+	}
+	return resp
+}
+
+// ReadConfigTx 读取configtx.yaml
+func ReadConfigTx(path string) (*ConfigTx, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile:%w", err)
+	}
+	var tx ConfigTx
+	if err := yaml.Unmarshal(data, &tx); err != nil {
+		return nil, fmt.Errorf("Unmarshal:%w", err)
+	}
+	return &tx, nil
+}
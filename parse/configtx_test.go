@@ -0,0 +1,98 @@
+package parse
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testConfigTx = `
+Organizations:
+  - &OrdererOrg
+    Name: OrdererOrg
+    ID: OrdererMSP
+    MSPDir: ../organizations/ordererOrganizations/example.com/msp
+  - &Org1
+    Name: Org1MSP
+    ID: Org1MSP
+    MSPDir: ../organizations/peerOrganizations/org1.example.com/msp
+  - &Org2
+    Name: Org2MSP
+    ID: Org2MSP
+    MSPDir: ../organizations/peerOrganizations/org2.example.com/msp
+  - &Org3
+    Name: Org3MSP
+    ID: Org3MSP
+    MSPDir: msp
+Profiles:
+  TwoOrgsChannel:
+    Orderer:
+      Organizations:
+        - *OrdererOrg
+      EtcdRaft:
+        Consenters:
+          - Host: orderer.example.com
+            Port: 7050
+    Application:
+      Organizations:
+        - *Org1
+        - *Org2
+  OrdererGenesis:
+    Orderer:
+      Organizations:
+        - *OrdererOrg
+`
+
+func TestReadConfigTx(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "configtx.yaml")
+	if err := os.WriteFile(path, []byte(testConfigTx), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := ReadConfigTx(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, ok := tx.Profiles["TwoOrgsChannel"]
+	if !ok || p.Application == nil || p.Orderer == nil {
+		t.Fatalf("profile TwoOrgsChannel: %+v", p)
+	}
+	if got := len(p.Application.Organizations); got != 2 {
+		t.Fatalf("application organizations: %d", got)
+	}
+	if got := p.Orderer.EtcdRaft.Consenters; len(got) != 1 || got[0].Host != "orderer.example.com" || got[0].Port != 7050 {
+		t.Fatalf("consenters: %+v", got)
+	}
+	if p := tx.Profiles["OrdererGenesis"]; p.Application != nil {
+		t.Fatalf("profile OrdererGenesis: unexpected application")
+	}
+
+	// MSPDir无法推断组织名称时不包含在内
+	want := map[string]string{
+		"example.com":      "OrdererMSP",
+		"org1.example.com": "Org1MSP",
+		"org2.example.com": "Org2MSP",
+	}
+	if got := tx.MspIds(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("MspIds: got %v want %v", got, want)
+	}
+
+	if _, err := ReadConfigTx(filepath.Join(t.TempDir(), "none.yaml")); err == nil {
+		t.Fatal("ReadConfigTx: expected error for missing file")
+	}
+}
+
+func TestConfigTxOrgName(t *testing.T) {
+	for dir, want := range map[string]string{
+		"../organizations/peerOrganizations/org1.example.com/msp": "org1.example.com",
+		"crypto-config/ordererOrganizations/example.com/msp":      "example.com",
+		"peerOrganizations": "",
+		"msp":               "",
+		"":                  "",
+	} {
+		if got := (ConfigTxOrg{MSPDir: dir}).OrgName(); got != want {
+			t.Fatalf("OrgName(%q): got %q want %q", dir, got, want)
+		}
+	}
+}
@@ -17,10 +17,10 @@ func New(cfg Config) *Client {
 	return &cli
 }
 
-func (c *client) Do() {
+func (c *Client) Do() {
 
 }
 
-func (c *client) ReadDir(path string) {
+func (c *Client) ReadDir(path string) {
 
 }
@@ -3,14 +3,25 @@ package host
 import (
 	"context"
 	"log"
+	"net"
 	"strings"
 )
 
+const (
+	PlaceholderIP   = "${IP}"   // 未能解析出ip时写入配置中的占位符
+	PlaceholderPort = "${PORT}" // 未能解析出端口时写入配置中的占位符
+)
+
 type FetchHost interface {
 	GetHost(domain string) (host Host, ok bool)
 	Close() error
 }
 
+// FetchTLS 从容器环境变量中获取节点是否开启tls,节点发现支持时实现
+type FetchTLS interface {
+	GetTLS(domain string) (enabled bool, ok bool)
+}
+
 type Host string
 
 func (d Host) Port() string {
@@ -29,7 +40,7 @@ func (d Host) Port() string {
 	// 7051/tcp todo:
 
 	// :::9051->9051/tcp todo:
-	return "${PORT}"
+	return PlaceholderPort
 }
 
 // IP .
@@ -50,7 +61,20 @@ func (d Host) IP() string {
 
 	// :::9051->9051/tcp todo:
 
-	return "${IP}"
+	return PlaceholderIP
+}
+
+// Advertise 地址为0.0.0.0等通配地址时替换为指定的ip,例如远程主机地址
+func (d Host) Advertise(ip string) Host {
+	port := d.Port()
+	if port == PlaceholderPort {
+		return d
+	}
+	switch d.IP() {
+	case "0.0.0.0", "", "::", PlaceholderIP:
+		return Host(net.JoinHostPort(ip, port))
+	}
+	return d
 }
 
 func StrToMap(raw string, sep string) map[string]string {
@@ -75,11 +99,22 @@ func New(ctx context.Context, mode string, cfg *Config) (FetchHost, error) {
 	case "ftp":
 		// todo:
 	case "sftp":
-		resp, err = NewSSH(ctx, cfg)
+		if len(cfg.Targets) > 0 {
+			resp, err = NewMulti(ctx, cfg)
+		} else {
+			resp, err = NewSSH(ctx, cfg)
+		}
 	default:
 		// 执行host解析
 		// resp, err = NewHostResolver(ctx)
 	}
+	// 远程模式下主机校验、认证等错误直接返回,不能回退为本机或者默认值
+	if mode == "sftp" {
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
 	if err != nil {
 		log.Printf("[host] New:%s\n", err)
 	}
@@ -87,8 +122,12 @@ func New(ctx context.Context, mode string, cfg *Config) (FetchHost, error) {
 		return resp, nil
 	}
 
-	// 从docker中尝试读取解析如果失败则使用本地默认值
-	resp, err = NewLocalDocker(ctx)
+	// 从本机容器运行时中尝试读取解析如果失败则使用本地默认值
+	rt, err := ParseRuntime(cfg.Runtime)
+	if err != nil {
+		return nil, err
+	}
+	resp, err = NewLocal(ctx, rt)
 	if err == nil {
 		return resp, nil
 	}
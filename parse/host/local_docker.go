@@ -10,44 +10,48 @@ import (
 	"time"
 )
 
-const cmdArgs = `docker ps --format "table{{.Image}}\t{{.Names}}\t{{.Ports}}" | grep "hyperledger/fabric-peer\|hyperledger/fabric-orderer" | awk '{print $2,$3}'`
-
-type docker struct {
-	store map[string]string
-}
-
-// NewLocalDocker 考虑使用docker api来实现此功能
-func NewLocalDocker(ctx context.Context) (FetchHost, error) {
+// localExec 在本机执行命令
+func localExec(ctx context.Context, args ...string) ([]byte, error) {
 	var (
-		dk     docker
 		stdout bytes.Buffer
 		stderr bytes.Buffer
 	)
-
-	ctx, cancel := context.WithTimeout(ctx, time.Second*15)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, "bash", "-c", cmdArgs)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		log.Println("[NewLocalDocker] GetMspId:", stderr.String())
-		return nil, fmt.Errorf("run: %s", stderr.String())
+		return nil, fmt.Errorf("run: %w: %s", err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+type local struct {
+	*containers
+}
+
+// NewLocal 通过本机容器运行时(docker podman nerdctl)发现节点地址以及mspid
+func NewLocal(ctx context.Context, rt Runtime) (FetchHost, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*15)
+	defer cancel()
+
+	list, err := rt.Containers(ctx, localExec)
+	if err != nil {
+		log.Printf("[NewLocal] %s: %s\n", rt, err)
+		return nil, fmt.Errorf("containers: %w", err)
 	}
-	resp := stdout.String()
-	dk.store = StrToMap(resp, " ")
-	log.Printf("[NewLocalDocker] stdout:\n%sparse: %+v\n", resp, dk.store)
-	if len(dk.store) == 0 {
+	l := local{containers: newContainers(list, "")}
+	log.Printf("[NewLocal] %s parse: %+v\n", rt, l.hosts)
+	if len(l.hosts) == 0 {
 		return nil, errors.New("is empty")
 	}
-	return &dk, nil
+	return &l, nil
 }
 
-func (d *docker) GetHost(domain string) (host Host, ok bool) {
-	m, ok := d.store[domain]
-	host = Host(m)
-	return
+// NewLocalDocker 通过本机docker发现节点地址
+func NewLocalDocker(ctx context.Context) (FetchHost, error) {
+	return NewLocal(ctx, Docker)
 }
 
-func (d *docker) Close() error {
+func (l *local) Close() error {
 	return nil
 }
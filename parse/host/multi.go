@@ -0,0 +1,199 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Target 远程主机,同一次生成中可以配置多台主机分别发现各自运行的节点
+type Target struct {
+	Addr     string   `json:"addr" yaml:"addr"`                       // ip加端口,不带端口时默认22
+	Username string   `json:"user,omitempty" yaml:"user,omitempty"`   // 为空时使用Config.Username
+	Scope    []string `json:"scope,omitempty" yaml:"scope,omitempty"` // 组织域名或节点域名,为空时采用该主机发现的所有节点
+}
+
+// ParseTarget 解析 [user@]host[:port][=scope1,scope2] 格式的远程主机
+func ParseTarget(raw string) (Target, error) {
+	var t Target
+	addr := raw
+	if i := strings.Index(raw, "="); i >= 0 {
+		addr = raw[:i]
+		for _, s := range strings.Split(raw[i+1:], ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				t.Scope = append(t.Scope, s)
+			}
+		}
+	}
+	t.Username, t.Addr = splitUser(strings.TrimSpace(addr), "")
+	if t.Addr == "" {
+		return Target{}, fmt.Errorf("target %q addr is empty", raw)
+	}
+	return t, nil
+}
+
+// Match 判断节点域名是否属于该主机的发现范围
+func (t Target) Match(domain string) bool {
+	if len(t.Scope) == 0 {
+		return true
+	}
+	for _, s := range t.Scope {
+		if domain == s || strings.HasSuffix(domain, "."+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Claim 某台主机发现的节点地址
+type Claim struct {
+	Addr string `json:"addr"` // 远程主机地址
+	Host Host   `json:"host"` // 发现的节点地址
+}
+
+// Conflict 多台主机发现了同一个节点域名,采用第一台主机的结果
+type Conflict struct {
+	Domain string  `json:"domain"`
+	Claims []Claim `json:"claims"`
+}
+
+// Multi 并发从多台远程主机中发现节点地址并合并
+type Multi struct {
+	store     map[string]Claim
+	msp       map[string]string
+	tls       map[string]bool
+	conflicts []Conflict
+}
+
+// NewMulti 按cfg.Targets并发登录远程主机,并发数量由cfg.Workers控制,
+// 结果按Targets顺序合并,同一域名被多台主机发现时记录冲突
+func NewMulti(ctx context.Context, cfg *Config) (*Multi, error) {
+	if len(cfg.Targets) == 0 {
+		return nil, fmt.Errorf("targets is empty")
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+	// 并发连接前解析一次私钥,私钥加密时只询问一次密码
+	var signer = cfg.Signer
+	if signer == nil && cfg.PrivateKey != "" {
+		var err error
+		if signer, err = cfg.signer(); err != nil {
+			return nil, err
+		}
+	}
+
+	type result struct {
+		store *containers
+		err   error
+	}
+	var (
+		results = make([]result, len(cfg.Targets))
+		sem     = make(chan struct{}, workers)
+		wg      sync.WaitGroup
+	)
+	for i, t := range cfg.Targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i].err = ctx.Err()
+				return
+			}
+
+			c := *cfg
+			c.Addr = t.Addr
+			c.Targets = nil
+			c.Signer = signer
+			if t.Username != "" {
+				c.Username = t.Username
+			}
+			s, err := NewSSH(ctx, &c)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			results[i].store = s.store
+			s.Close()
+		}(i, t)
+	}
+	wg.Wait()
+
+	var (
+		m = &Multi{store: make(map[string]Claim), msp: make(map[string]string), tls: make(map[string]bool)}
+		// 同一个域名被哪些主机发现
+		claims = make(map[string][]Claim)
+		failed int
+	)
+	for i, r := range results {
+		t := cfg.Targets[i]
+		if r.err != nil {
+			failed++
+			log.Printf("[NewMulti] %s: %s\n", t.Addr, r.err)
+			continue
+		}
+		for domain, h := range r.store.hosts {
+			if !t.Match(domain) {
+				continue
+			}
+			claims[domain] = append(claims[domain], Claim{Addr: t.Addr, Host: h})
+		}
+		for org, id := range r.store.msp {
+			if _, ok := m.msp[org]; !ok && t.Match(org) {
+				m.msp[org] = id
+			}
+		}
+		for domain, enabled := range r.store.tls {
+			if _, ok := m.tls[domain]; !ok && t.Match(domain) {
+				m.tls[domain] = enabled
+			}
+		}
+	}
+	if failed == len(results) {
+		return nil, fmt.Errorf("all %d targets failed", failed)
+	}
+
+	for domain, list := range claims {
+		m.store[domain] = list[0]
+		if len(list) > 1 {
+			m.conflicts = append(m.conflicts, Conflict{Domain: domain, Claims: list})
+			log.Printf("[NewMulti] warn %s claimed by %d hosts, use %s\n", domain, len(list), list[0].Addr)
+		}
+	}
+	sort.Slice(m.conflicts, func(i, j int) bool { return m.conflicts[i].Domain < m.conflicts[j].Domain })
+	return m, nil
+}
+
+func (m *Multi) GetHost(domain string) (host Host, ok bool) {
+	c, ok := m.store[domain]
+	host = c.Host
+	return
+}
+
+// GetMspId 根据组织域名或者节点域名查询mspid
+func (m *Multi) GetMspId(org string) (id string, ok bool) {
+	id, ok = m.msp[org]
+	return
+}
+
+// GetTLS 根据节点域名查询是否开启tls
+func (m *Multi) GetTLS(domain string) (enabled bool, ok bool) {
+	enabled, ok = m.tls[domain]
+	return
+}
+
+// Conflicts 返回被多台主机同时发现的节点域名
+func (m *Multi) Conflicts() []Conflict {
+	return m.conflicts
+}
+
+func (m *Multi) Close() error {
+	return nil
+}
@@ -0,0 +1,112 @@
+package host
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseTarget(t *testing.T) {
+	got, err := ParseTarget("admin@10.0.0.1:2222=org1.example.com, peer0.org2.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Target{
+		Addr:     "10.0.0.1:2222",
+		Username: "admin",
+		Scope:    []string{"org1.example.com", "peer0.org2.example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+	if !want.Match("peer1.org1.example.com") || want.Match("peer1.org2.example.com") {
+		t.Fatal("Match: unexpected scope result")
+	}
+	if _, err := ParseTarget("=org1.example.com"); err == nil {
+		t.Fatal("expected empty addr error")
+	}
+}
+
+func TestNewMulti(t *testing.T) {
+	a := newTestServer(t, nil)
+	b := newTestServer(t, nil)
+	m, err := NewMulti(context.Background(), &Config{
+		Username:   "root",
+		Password:   "secret",
+		KnownHosts: knownHostsFile(t, a, b),
+		Workers:    1,
+		Targets: []Target{
+			{Addr: a.addr, Scope: []string{"org1.example.com"}},
+			{Addr: b.addr},
+			{Addr: "127.0.0.1:1"}, // 不可达的主机不影响其他主机结果
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewMulti: %s", err)
+	}
+	defer m.Close()
+
+	if _, ok := m.GetHost("orderer.example.com"); !ok {
+		t.Fatal("GetHost: orderer.example.com not found")
+	}
+	conflicts := m.Conflicts()
+	if len(conflicts) != 1 || conflicts[0].Domain != "peer0.org1.example.com" || len(conflicts[0].Claims) != 2 {
+		t.Fatalf("Conflicts: got %+v", conflicts)
+	}
+	if conflicts[0].Claims[0].Addr != a.addr {
+		t.Fatalf("Conflicts: first claim should come from %s, got %s", a.addr, conflicts[0].Claims[0].Addr)
+	}
+}
+
+func TestNewMultiEncryptedKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(key, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	a := newTestServer(t, sshPub)
+	b := newTestServer(t, sshPub)
+	var prompts int32
+	m, err := NewMulti(context.Background(), &Config{
+		Username:   "root",
+		PrivateKey: key,
+		KnownHosts: knownHostsFile(t, a, b),
+		Workers:    2,
+		Targets:    []Target{{Addr: a.addr}, {Addr: b.addr}},
+		Prompt: func(msg string) ([]byte, error) {
+			atomic.AddInt32(&prompts, 1)
+			return []byte("passphrase"), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewMulti: %s", err)
+	}
+	defer m.Close()
+	// 所有主机共用一次解析的私钥
+	if prompts != 1 {
+		t.Fatalf("prompted %d times, want 1", prompts)
+	}
+	if _, ok := m.GetHost("orderer.example.com"); !ok {
+		t.Fatal("GetHost: orderer.example.com not found")
+	}
+}
@@ -0,0 +1,277 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Runtime 容器运行时
+type Runtime string
+
+const (
+	Docker  Runtime = "docker"
+	Podman  Runtime = "podman"
+	Nerdctl Runtime = "nerdctl" // containerd
+)
+
+// ParseRuntime 解析容器运行时名称,为空时默认docker
+func ParseRuntime(name string) (Runtime, error) {
+	switch r := Runtime(strings.ToLower(strings.TrimSpace(name))); r {
+	case "":
+		return Docker, nil
+	case Docker, Podman, Nerdctl:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unsupported runtime %q, expect docker|podman|nerdctl", name)
+	}
+}
+
+// Exec 执行命令并返回标准输出,本地通过os/exec执行,远程通过ssh执行
+type Exec func(ctx context.Context, args ...string) ([]byte, error)
+
+// Container 运行中的fabric容器
+type Container struct {
+	Name  string            // 容器名称,通常为节点域名 eg: peer0.org1.example.com
+	Kind  string            // peer orderer ca
+	Image string            // 镜像
+	Env   map[string]string // 环境变量
+	Ports map[string]string // 容器端口到主机地址 eg: 7051/tcp => 0.0.0.0:7051
+}
+
+// inspect docker/podman/nerdctl inspect 输出中用到的字段,三者格式兼容
+type inspect struct {
+	Name   string `json:"Name"`
+	Config struct {
+		Image string   `json:"Image"`
+		Env   []string `json:"Env"`
+	} `json:"Config"`
+	NetworkSettings struct {
+		Ports map[string][]struct {
+			HostIp   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"Ports"`
+	} `json:"NetworkSettings"`
+}
+
+// Containers 列出运行中的fabric peer、orderer、ca容器
+func (r Runtime) Containers(ctx context.Context, exec Exec) ([]Container, error) {
+	out, err := exec(ctx, string(r), "ps", "-q")
+	if err != nil {
+		return nil, fmt.Errorf("%s ps:%w", r, err)
+	}
+	ids := strings.Fields(string(out))
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%s ps: no running container", r)
+	}
+
+	out, err = exec(ctx, append([]string{string(r), "inspect"}, ids...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s inspect:%w", r, err)
+	}
+	var list []inspect
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("%s inspect Unmarshal:%w", r, err)
+	}
+
+	var resp = make([]Container, 0, len(list))
+	for _, v := range list {
+		c := Container{
+			Name:  strings.TrimPrefix(v.Name, "/"),
+			Image: v.Config.Image,
+			Env:   make(map[string]string, len(v.Config.Env)),
+			Ports: make(map[string]string, len(v.NetworkSettings.Ports)),
+		}
+		for _, e := range v.Config.Env {
+			if kv := strings.SplitN(e, "=", 2); len(kv) == 2 {
+				c.Env[kv[0]] = kv[1]
+			}
+		}
+		for port, binds := range v.NetworkSettings.Ports {
+			// 同时绑定ipv4和ipv6时优先使用ipv4
+			for _, b := range binds {
+				if b.HostPort == "" {
+					continue
+				}
+				if _, ok := c.Ports[port]; ok && strings.Contains(b.HostIp, ":") {
+					continue
+				}
+				ip := b.HostIp
+				if ip == "" || ip == "::" {
+					ip = "0.0.0.0"
+				}
+				c.Ports[port] = net.JoinHostPort(ip, b.HostPort)
+			}
+		}
+		if c.Kind = kind(c); c.Kind == "" {
+			continue
+		}
+		resp = append(resp, c)
+	}
+	sort.Slice(resp, func(i, j int) bool { return resp[i].Name < resp[j].Name })
+	return resp, nil
+}
+
+// kind 根据镜像或者环境变量判断容器类型
+func kind(c Container) string {
+	switch {
+	case strings.Contains(c.Image, "fabric-peer") || c.Env["CORE_PEER_ID"] != "":
+		return "peer"
+	case strings.Contains(c.Image, "fabric-orderer") || c.Env["ORDERER_GENERAL_LISTENPORT"] != "":
+		return "orderer"
+	case strings.Contains(c.Image, "fabric-ca") || c.Env["FABRIC_CA_SERVER_HOME"] != "":
+		return "ca"
+	}
+	return ""
+}
+
+// envPort 从环境变量中取端口,值可以是端口或者地址
+func envPort(env map[string]string, keys ...string) string {
+	for _, k := range keys {
+		v := env[k]
+		if v == "" {
+			continue
+		}
+		if _, port, err := net.SplitHostPort(v); err == nil {
+			return port
+		}
+		return v
+	}
+	return ""
+}
+
+// ListenPort 节点服务监听的容器端口
+func (c Container) ListenPort() string {
+	var port string
+	switch c.Kind {
+	case "peer":
+		if port = envPort(c.Env, "CORE_PEER_LISTENADDRESS", "CORE_PEER_ADDRESS"); port == "" {
+			port = "7051"
+		}
+	case "orderer":
+		if port = envPort(c.Env, "ORDERER_GENERAL_LISTENPORT"); port == "" {
+			port = "7050"
+		}
+	case "ca":
+		if port = envPort(c.Env, "FABRIC_CA_SERVER_PORT"); port == "" {
+			port = "7054"
+		}
+	}
+	return port
+}
+
+// Domains 容器对应的节点域名,包含容器名称、peer节点id以及CA证书中声明的主机域名,
+// CA名称(FABRIC_CA_SERVER_CA_NAME)不是域名,见CAName
+func (c Container) Domains() []string {
+	var (
+		list = []string{c.Name}
+		seen = map[string]bool{c.Name: true}
+	)
+	add := func(v string) {
+		if v = strings.TrimSpace(v); v != "" && !seen[v] {
+			seen[v] = true
+			list = append(list, v)
+		}
+	}
+	add(c.Env["CORE_PEER_ID"])
+	if c.Kind == "ca" {
+		// FABRIC_CA_SERVER_CSR_HOSTS=ca.org1.example.com,localhost,127.0.0.1 只取域名
+		for _, v := range strings.Split(c.Env["FABRIC_CA_SERVER_CSR_HOSTS"], ",") {
+			if v = strings.TrimSpace(v); strings.Contains(v, ".") && net.ParseIP(v) == nil {
+				add(v)
+			}
+		}
+	}
+	return list
+}
+
+// CAName CA服务名称,对应sdk配置中certificateAuthorities.caName,只作为元数据使用
+func (c Container) CAName() string {
+	if c.Kind != "ca" {
+		return ""
+	}
+	return c.Env["FABRIC_CA_SERVER_CA_NAME"]
+}
+
+// MspId 容器环境变量中的本地mspid
+func (c Container) MspId() string {
+	for _, k := range []string{"CORE_PEER_LOCALMSPID", "ORDERER_GENERAL_LOCALMSPID"} {
+		if v := c.Env[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// TLS 容器环境变量中节点是否开启tls,未声明时ok为false
+func (c Container) TLS() (enabled bool, ok bool) {
+	for _, k := range []string{"CORE_PEER_TLS_ENABLED", "ORDERER_GENERAL_TLS_ENABLED", "FABRIC_CA_SERVER_TLS_ENABLED"} {
+		if v, err := strconv.ParseBool(c.Env[k]); err == nil {
+			return v, true
+		}
+	}
+	return false, false
+}
+
+// containers 容器发现结果,提供节点地址、mspid以及tls开启状态查询
+type containers struct {
+	hosts map[string]Host   // key为节点域名
+	msp   map[string]string // key为节点域名以及组织域名
+	tls   map[string]bool   // key为节点域名
+}
+
+// newContainers advertise不为空时,端口绑定在通配地址上的节点使用该地址代替
+func newContainers(list []Container, advertise string) *containers {
+	var c = containers{
+		hosts: make(map[string]Host, len(list)),
+		msp:   make(map[string]string, len(list)),
+		tls:   make(map[string]bool, len(list)),
+	}
+	for _, v := range list {
+		addr, ok := v.Ports[v.ListenPort()+"/tcp"]
+		enabled, tlsOk := v.TLS()
+		for _, domain := range v.Domains() {
+			if tlsOk {
+				c.tls[domain] = enabled
+			}
+			if ok {
+				h := Host(addr)
+				if advertise != "" {
+					h = h.Advertise(advertise)
+				}
+				c.hosts[domain] = h
+			}
+			if id := v.MspId(); id != "" {
+				c.msp[domain] = id
+				// peer0.org1.example.com => org1.example.com
+				if i := strings.Index(domain, "."); i > 0 {
+					if _, ok := c.msp[domain[i+1:]]; !ok {
+						c.msp[domain[i+1:]] = id
+					}
+				}
+			}
+		}
+	}
+	return &c
+}
+
+func (c *containers) GetHost(domain string) (host Host, ok bool) {
+	host, ok = c.hosts[domain]
+	return
+}
+
+// GetMspId 根据组织域名或者节点域名查询mspid
+func (c *containers) GetMspId(org string) (id string, ok bool) {
+	id, ok = c.msp[org]
+	return
+}
+
+// GetTLS 根据节点域名查询是否开启tls
+func (c *containers) GetTLS(domain string) (enabled bool, ok bool) {
+	enabled, ok = c.tls[domain]
+	return
+}
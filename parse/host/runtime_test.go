@@ -0,0 +1,124 @@
+package host
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseRuntime(t *testing.T) {
+	for name, want := range map[string]Runtime{"": Docker, "Podman": Podman, "nerdctl": Nerdctl} {
+		got, err := ParseRuntime(name)
+		if err != nil || got != want {
+			t.Fatalf("ParseRuntime(%q): got %q %v", name, got, err)
+		}
+	}
+	if _, err := ParseRuntime("lxc"); err == nil {
+		t.Fatal("expected unsupported runtime error")
+	}
+}
+
+func TestContainersPodman(t *testing.T) {
+	const inspect = `[{
+  "Name": "peer1.org2.example.com",
+  "Config": {
+    "Image": "docker.io/hyperledger/fabric-peer:2.5",
+    "Env": ["CORE_PEER_ADDRESS=peer1.org2.example.com:10051", "CORE_PEER_LOCALMSPID=Org2MSP", "CORE_PEER_TLS_ENABLED=false"]
+  },
+  "NetworkSettings": {"Ports": {"10051/tcp": [{"HostIp": "", "HostPort": "10051"}]}}
+}]`
+	exec := func(ctx context.Context, args ...string) ([]byte, error) {
+		if args[0] != "podman" {
+			t.Fatalf("unexpected runtime %s", args[0])
+		}
+		if args[1] == "inspect" {
+			return []byte(inspect), nil
+		}
+		return []byte("3f2a\n"), nil
+	}
+
+	list, err := Podman.Containers(context.Background(), exec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Kind != "peer" || list[0].ListenPort() != "10051" {
+		t.Fatalf("Containers: got %+v", list)
+	}
+
+	c := newContainers(list, "")
+	if h, ok := c.GetHost("peer1.org2.example.com"); !ok || h.Port() != "10051" || h.IP() != "0.0.0.0" {
+		t.Fatalf("GetHost: got %q %v", h, ok)
+	}
+	if id, ok := c.GetMspId("org2.example.com"); !ok || id != "Org2MSP" {
+		t.Fatalf("GetMspId: got %q %v", id, ok)
+	}
+	if enabled, ok := c.GetTLS("peer1.org2.example.com"); !ok || enabled {
+		t.Fatalf("GetTLS: got %v %v", enabled, ok)
+	}
+	if _, ok := c.GetTLS("peer0.org2.example.com"); ok {
+		t.Fatal("GetTLS: unexpected peer0")
+	}
+}
+
+func TestContainersEmpty(t *testing.T) {
+	exec := func(ctx context.Context, args ...string) ([]byte, error) {
+		return []byte(strings.Repeat(" ", 3)), nil
+	}
+	if _, err := Docker.Containers(context.Background(), exec); err == nil {
+		t.Fatal("expected no running container error")
+	}
+}
+
+func TestContainerDomains(t *testing.T) {
+	list := []Container{
+		{
+			Name:  "ca_org1",
+			Kind:  "ca",
+			Env:   map[string]string{"FABRIC_CA_SERVER_CA_NAME": "ca-org1", "FABRIC_CA_SERVER_CSR_HOSTS": "ca.org1.example.com, localhost,127.0.0.1", "FABRIC_CA_SERVER_TLS_ENABLED": "true"},
+			Ports: map[string]string{"7054/tcp": "0.0.0.0:7054"},
+		},
+		{
+			Name:  "ca_org2",
+			Kind:  "ca",
+			Env:   map[string]string{"FABRIC_CA_SERVER_CA_NAME": "ca-org2", "FABRIC_CA_SERVER_PORT": "8054"},
+			Ports: map[string]string{"8054/tcp": "0.0.0.0:8054"},
+		},
+		{
+			Name:  "peer0",
+			Kind:  "peer",
+			Env:   map[string]string{"CORE_PEER_ID": "peer0.org1.example.com", "FABRIC_CA_SERVER_CA_NAME": "ca-org1"},
+			Ports: map[string]string{"7051/tcp": "0.0.0.0:7051"},
+		},
+	}
+	for i, want := range [][]string{
+		{"ca_org1", "ca.org1.example.com"},
+		{"ca_org2"},
+		{"peer0", "peer0.org1.example.com"},
+	} {
+		if got := list[i].Domains(); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("Domains(%s): got %v want %v", list[i].Name, got, want)
+		}
+	}
+	for i, want := range []string{"ca-org1", "ca-org2", ""} {
+		if got := list[i].CAName(); got != want {
+			t.Fatalf("CAName(%s): got %q want %q", list[i].Name, got, want)
+		}
+	}
+
+	c := newContainers(list, "")
+	if h, ok := c.GetHost("ca.org1.example.com"); !ok || h.Port() != "7054" {
+		t.Fatalf("GetHost: got %q %v", h, ok)
+	}
+	if enabled, ok := c.GetTLS("ca.org1.example.com"); !ok || !enabled {
+		t.Fatalf("GetTLS: got %v %v", enabled, ok)
+	}
+	if h, ok := c.GetHost("ca_org2"); !ok || h.Port() != "8054" {
+		t.Fatalf("GetHost: got %q %v", h, ok)
+	}
+	// CA名称不作为域名
+	for _, name := range []string{"ca-org1", "ca-org2", "localhost"} {
+		if h, ok := c.GetHost(name); ok {
+			t.Fatalf("GetHost(%s): unexpected %q", name, h)
+		}
+	}
+}
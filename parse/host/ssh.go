@@ -5,19 +5,40 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
 )
 
 type Config struct {
-	Addr       string `json:"addr,omitempty" yaml:"addr"`               // ip加端口
-	Username   string `json:"user,omitempty" yaml:"user"`               // 用户名
-	Password   string `json:"password,omitempty" yaml:"password"`       // 密码
-	PrivateKey string `json:"private_key,omitempty" yaml:"private_key"` // eg: /home/user/.ssh/id_rsa"
-	Gssapi     string `json:"gssapi,omitempty" yaml:"gssapi"`           //
+	Addr        string        `json:"addr,omitempty" yaml:"addr"`               // ip加端口,不带端口时默认22
+	Username    string        `json:"user,omitempty" yaml:"user"`               // 用户名
+	Password    string        `json:"password,omitempty" yaml:"password"`       // 密码
+	PrivateKey  string        `json:"private_key,omitempty" yaml:"private_key"` // eg: /home/user/.ssh/id_rsa"
+	Passphrase  string        `json:"passphrase,omitempty" yaml:"passphrase"`   // 私钥密码,私钥加密且为空时通过Prompt询问
+	Agent       bool          `json:"agent,omitempty" yaml:"agent"`             // 使用ssh-agent认证,读取环境变量SSH_AUTH_SOCK
+	KnownHosts  string        `json:"known_hosts,omitempty" yaml:"known_hosts"` // known_hosts文件路径,默认~/.ssh/known_hosts
+	Fingerprint string        `json:"fingerprint,omitempty" yaml:"fingerprint"` // 固定服务端公钥指纹 eg: SHA256:xxx 设置后不再读取known_hosts
+	Insecure    bool          `json:"insecure,omitempty" yaml:"insecure"`       // 跳过服务端公钥校验,仅用于测试环境
+	Jump        []string      `json:"jump,omitempty" yaml:"jump"`               // 跳板机 [user@]host[:port] 按顺序连接
+	Timeout     time.Duration `json:"timeout,omitempty" yaml:"timeout"`         // 建立连接超时时间,默认15s
+	Gssapi      string        `json:"gssapi,omitempty" yaml:"gssapi"`           // 暂不支持
+	Targets     []Target      `json:"targets,omitempty" yaml:"targets"`         // 多台远程主机,设置后忽略Addr
+	Workers     int           `json:"workers,omitempty" yaml:"workers"`         // 多台主机并发发现的数量,默认4
+	Runtime     string        `json:"runtime,omitempty" yaml:"runtime"`         // 容器运行时 docker(默认) podman nerdctl
+
+	// Prompt 私钥加密并且没有配置Passphrase时调用,用于交互式输入私钥密码
+	Prompt func(msg string) ([]byte, error) `json:"-" yaml:"-"`
+	// Signer 已解析的私钥,设置后不再读取PrivateKey,多台主机共用避免重复询问私钥密码
+	Signer ssh.Signer `json:"-" yaml:"-"`
 }
 
 func (c *Config) Valid() error {
@@ -27,93 +48,328 @@ func (c *Config) Valid() error {
 	if c.Addr == "" {
 		return fmt.Errorf("addr is empty")
 	}
-	if c.Password == "" && c.PrivateKey == "" {
+	if c.Gssapi != "" {
+		return fmt.Errorf("gssapi is not supported")
+	}
+	if c.Password == "" && c.PrivateKey == "" && c.Signer == nil && !c.Agent {
 		return fmt.Errorf("auth is empty")
 	}
 	return nil
 }
 
-type SSH struct {
-	*ssh.Client
-	store map[string]string
-}
+// hostKeyCallback 服务端公钥校验,优先使用固定指纹,其次known_hosts
+func (c *Config) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if c.Insecure {
+		log.Printf("[ssh] warn host key verification is disabled\n")
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	if c.Fingerprint != "" {
+		return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			if fp := ssh.FingerprintSHA256(key); fp != c.Fingerprint {
+				return fmt.Errorf("host key fingerprint mismatch for %s: got %s want %s", hostname, fp, c.Fingerprint)
+			}
+			return nil
+		}, nil
+	}
 
-func NewSSH(ctx context.Context, cfg *Config) (*SSH, error) {
-	log.Printf("ssh config:%+v\n", cfg)
-	if err := cfg.Valid(); err != nil {
-		return nil, fmt.Errorf("valid: %w", err)
+	path := c.KnownHosts
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("UserHomeDir:%w", err)
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("knownhosts:%w", err)
 	}
+	return callback, nil
+}
+
+// authMethods 认证方式,返回的closer用于关闭ssh-agent连接
+func (c *Config) authMethods() ([]ssh.AuthMethod, io.Closer, error) {
 	var (
-		// pubKey ssh.PublicKey
-		auth []ssh.AuthMethod
+		auth   []ssh.AuthMethod
+		closer io.Closer
 	)
-	if cfg.Password != "" {
-		auth = append(auth, ssh.RetryableAuthMethod(ssh.Password(cfg.Password), 1))
-	}
-	if cfg.PrivateKey != "" {
-		key, err := os.ReadFile(cfg.PrivateKey)
+	if c.Agent {
+		sock := os.Getenv("SSH_AUTH_SOCK")
+		if sock == "" {
+			return nil, nil, errors.New("SSH_AUTH_SOCK is empty")
+		}
+		conn, err := net.Dial("unix", sock)
 		if err != nil {
-			return nil, fmt.Errorf("ReadFile:%w", err)
+			return nil, nil, fmt.Errorf("agent Dial:%w", err)
 		}
-		signer, err := ssh.ParsePrivateKey(key)
+		closer = conn
+		auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+	if c.Signer != nil {
+		auth = append(auth, ssh.PublicKeys(c.Signer))
+	} else if c.PrivateKey != "" {
+		signer, err := c.signer()
 		if err != nil {
-			return nil, fmt.Errorf("ParsePrivateKey:%w", err)
+			if closer != nil {
+				closer.Close()
+			}
+			return nil, nil, err
 		}
 		auth = append(auth, ssh.PublicKeys(signer))
 	}
-	if cfg.Gssapi != "" {
-		// auth = append(auth, ssh.GSSAPIWithMICAuthMethod())
+	if c.Password != "" {
+		auth = append(auth, ssh.RetryableAuthMethod(ssh.Password(c.Password), 1))
+	}
+	return auth, closer, nil
+}
+
+// signer 读取私钥,私钥加密时使用Passphrase或者Prompt解密
+func (c *Config) signer() (ssh.Signer, error) {
+	key, err := os.ReadFile(c.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile:%w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err == nil {
+		return signer, nil
+	}
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		return nil, fmt.Errorf("ParsePrivateKey:%w", err)
 	}
 
-	conf := &ssh.ClientConfig{
-		User:            cfg.Username,                // 连接登录的用户
-		Auth:            auth,                        // 认证方式
-		BannerCallback:  ssh.BannerDisplayStderr(),   // 错误显示到标准错误终端,可以自定义方法实现比如把错误信息写到文件中
-		Timeout:         time.Second * 15,            // 建立超时时间,0为永不超时
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // 貌似是限制指定秘钥类型
-		// HostKeyCallback:   ssh.FixedHostKey(pubKey),  // 貌似是限制指定秘钥类型
+	passphrase := []byte(c.Passphrase)
+	if len(passphrase) == 0 {
+		if c.Prompt == nil {
+			return nil, fmt.Errorf("private key %s is encrypted and passphrase is empty", c.PrivateKey)
+		}
+		if passphrase, err = c.Prompt(fmt.Sprintf("Enter passphrase for key '%s': ", c.PrivateKey)); err != nil {
+			return nil, fmt.Errorf("prompt:%w", err)
+		}
+	}
+	signer, err = ssh.ParsePrivateKeyWithPassphrase(key, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePrivateKeyWithPassphrase:%w", err)
+	}
+	return signer, nil
+}
+
+// withPort 地址没有端口时补充默认端口22
+func withPort(addr string) string {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return net.JoinHostPort(addr, "22")
+	}
+	return addr
+}
+
+// splitUser 拆分 user@host:port 格式
+func splitUser(target, user string) (string, string) {
+	if i := strings.LastIndex(target, "@"); i >= 0 {
+		return target[:i], target[i+1:]
+	}
+	return user, target
+}
+
+// handshake 在已建立的连接上完成ssh握手,ctx取消时中断
+func handshake(ctx context.Context, conn net.Conn, addr string, conf *ssh.ClientConfig) (*ssh.Client, error) {
+	type result struct {
+		client *ssh.Client
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		c, chans, reqs, err := ssh.NewClientConn(conn, addr, conf)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		done <- result{client: ssh.NewClient(c, chans, reqs)}
+	}()
+
+	select {
+	case <-ctx.Done():
+		conn.Close()
+		if r := <-done; r.client != nil {
+			r.client.Close()
+		}
+		return nil, ctx.Err()
+	case r := <-done:
+		if r.err != nil {
+			conn.Close()
+		}
+		return r.client, r.err
+	}
+}
+
+// Dial 建立ssh连接,配置了跳板机时依次经过跳板机连接到目标主机,返回的jumps需要调用方关闭
+func Dial(ctx context.Context, cfg *Config) (*ssh.Client, []*ssh.Client, error) {
+	if err := cfg.Valid(); err != nil {
+		return nil, nil, fmt.Errorf("valid: %w", err)
+	}
+	hostKey, err := cfg.hostKeyCallback()
+	if err != nil {
+		return nil, nil, fmt.Errorf("hostKeyCallback:%w", err)
 	}
-	conn, err := ssh.Dial("tcp", cfg.Addr, conf)
+	auth, closer, err := cfg.authMethods()
 	if err != nil {
-		// return nil, fmt.Errorf("Dial:%w", err)
-		log.Fatalf("Dial:%s", err)
+		return nil, nil, fmt.Errorf("authMethods:%w", err)
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = time.Second * 15
 	}
 
+	var (
+		client *ssh.Client
+		jumps  []*ssh.Client
+		hops   = append(append([]string{}, cfg.Jump...), cfg.Addr)
+	)
+	// 失败时关闭已经建立的连接
+	fail := func(err error) (*ssh.Client, []*ssh.Client, error) {
+		if client != nil {
+			client.Close()
+		}
+		for i := len(jumps) - 1; i >= 0; i-- {
+			jumps[i].Close()
+		}
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for i, hop := range hops {
+		user, addr := splitUser(hop, cfg.Username)
+		if i == len(hops)-1 {
+			user = cfg.Username
+		}
+		addr = withPort(addr)
+		conf := &ssh.ClientConfig{
+			User:            user,                      // 连接登录的用户
+			Auth:            auth,                      // 认证方式
+			BannerCallback:  ssh.BannerDisplayStderr(), // 错误显示到标准错误终端
+			Timeout:         timeout,                   // 建立超时时间
+			HostKeyCallback: hostKey,                   // 服务端公钥校验
+		}
+
+		var conn net.Conn
+		if client == nil {
+			var d net.Dialer
+			conn, err = d.DialContext(ctx, "tcp", addr)
+		} else {
+			conn, err = client.Dial("tcp", addr)
+		}
+		if err != nil {
+			return fail(fmt.Errorf("dial %s:%w", addr, err))
+		}
+
+		next, err := handshake(ctx, conn, addr, conf)
+		if err != nil {
+			return fail(fmt.Errorf("handshake %s:%w", addr, err))
+		}
+		if client != nil {
+			jumps = append(jumps, client)
+		}
+		client = next
+	}
+	return client, jumps, nil
+}
+
+// Run 在远程主机上执行命令,ctx取消时关闭会话
+func Run(ctx context.Context, client *ssh.Client, cmd string) ([]byte, error) {
 	var (
 		stdout bytes.Buffer
 		stderr bytes.Buffer
 	)
 	// 创建一个session用于执行命令行,相当于exec中的cmd命令行
-	session, err := conn.NewSession()
+	session, err := client.NewSession()
 	if err != nil {
 		return nil, fmt.Errorf("NewSession:%w", err)
 	}
 	defer session.Close()
 	session.Stdout = &stdout
 	session.Stderr = &stderr
-	if err := session.Run(cmdArgs); err != nil {
-		log.Printf("Run stderr:%s\n", stderr.String())
-		return nil, fmt.Errorf("run: %s", stderr.String())
-	}
-	resp := stdout.String()
-	log.Printf("[NewSSH] stdout:\n%s\n", resp)
-	store := StrToMap(resp, " ")
-	if len(store) == 0 {
-		return nil, errors.New("is empty")
+
+	done := make(chan error, 1)
+	go func() { done <- session.Run(cmd) }()
+	select {
+	case <-ctx.Done():
+		_ = session.Signal(ssh.SIGKILL)
+		session.Close()
+		return nil, ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return nil, fmt.Errorf("run: %w: %s", err, stderr.String())
+		}
+	}
+	return stdout.Bytes(), nil
+}
+
+type SSH struct {
+	*ssh.Client
+	jumps []*ssh.Client
+	store *containers
+}
+
+func NewSSH(ctx context.Context, cfg *Config) (*SSH, error) {
+	rt, err := ParseRuntime(cfg.Runtime)
+	if err != nil {
+		return nil, err
+	}
+	client, jumps, err := Dial(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("Dial:%w", err)
 	}
 	s := &SSH{
-		Client: conn,
-		store:  store,
+		Client: client,
+		jumps:  jumps,
+	}
+
+	list, err := rt.Containers(ctx, s.exec)
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("containers:%w", err)
+	}
+	// 容器端口绑定在通配地址上时,使用远程主机地址代替
+	ip, _, _ := net.SplitHostPort(withPort(cfg.Addr))
+	s.store = newContainers(list, ip)
+	log.Printf("[NewSSH] %s %s parse: %+v\n", cfg.Addr, rt, s.store.hosts)
+	if len(s.store.hosts) == 0 {
+		s.Close()
+		return nil, errors.New("is empty")
 	}
 	return s, nil
 }
 
+// exec 在远程主机上执行命令,参数使用单引号转义
+func (s *SSH) exec(ctx context.Context, args ...string) ([]byte, error) {
+	var quoted = make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, "'"+strings.Replace(a, "'", `'\''`, -1)+"'")
+	}
+	return Run(ctx, s.Client, strings.Join(quoted, " "))
+}
+
 func (s *SSH) GetHost(domain string) (host Host, ok bool) {
-	h, ok := s.store[domain]
-	host = Host(h)
-	return
+	return s.store.GetHost(domain)
+}
+
+// GetMspId 根据组织域名或者节点域名查询远程主机容器中的mspid
+func (s *SSH) GetMspId(org string) (id string, ok bool) {
+	return s.store.GetMspId(org)
+}
+
+// GetTLS 根据节点域名查询远程主机容器是否开启tls
+func (s *SSH) GetTLS(domain string) (enabled bool, ok bool) {
+	return s.store.GetTLS(domain)
 }
 
 func (s *SSH) Close() error {
-	return s.Client.Close()
+	err := s.Client.Close()
+	for i := len(s.jumps) - 1; i >= 0; i-- {
+		s.jumps[i].Close()
+	}
+	return err
 }
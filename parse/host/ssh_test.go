@@ -0,0 +1,348 @@
+package host
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const testInspect = `[
+  {
+    "Name": "/peer0.org1.example.com",
+    "Config": {
+      "Image": "hyperledger/fabric-peer:2.5",
+      "Env": ["CORE_PEER_ID=peer0.org1.example.com", "CORE_PEER_LISTENADDRESS=0.0.0.0:7051", "CORE_PEER_LOCALMSPID=Org1MSP"]
+    },
+    "NetworkSettings": {"Ports": {"7051/tcp": [{"HostIp": "0.0.0.0", "HostPort": "7051"}], "9444/tcp": [{"HostIp": "0.0.0.0", "HostPort": "9444"}]}}
+  },
+  {
+    "Name": "/orderer.example.com",
+    "Config": {
+      "Image": "hyperledger/fabric-orderer:2.5",
+      "Env": ["ORDERER_GENERAL_LISTENPORT=7050", "ORDERER_GENERAL_LOCALMSPID=OrdererMSP"]
+    },
+    "NetworkSettings": {"Ports": {"7050/tcp": [{"HostIp": "0.0.0.0", "HostPort": "7050"}, {"HostIp": "::", "HostPort": "7050"}]}}
+  },
+  {
+    "Name": "/couchdb0",
+    "Config": {"Image": "couchdb:3.3", "Env": []},
+    "NetworkSettings": {"Ports": {"5984/tcp": [{"HostIp": "0.0.0.0", "HostPort": "5984"}]}}
+  }
+]`
+
+// testCommand 模拟容器运行时命令的输出
+func testCommand(cmd string) string {
+	if strings.Contains(cmd, "'inspect'") {
+		return testInspect
+	}
+	return "a1\nb2\nc3\n"
+}
+
+// testServer 进程内ssh服务,exec请求返回固定的容器列表,支持direct-tcpip用于跳板机测试
+type testServer struct {
+	addr    string
+	hostKey ssh.Signer
+}
+
+func newTestServer(t *testing.T, authorized ssh.PublicKey) *testServer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "root" && string(pass) == "secret" {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if authorized != nil && string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	conf.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{addr: l.Addr().String(), hostKey: signer}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, conf)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) serve(conn net.Conn, conf *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, conf)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for nc := range chans {
+		switch nc.ChannelType() {
+		case "session":
+			ch, reqs, err := nc.Accept()
+			if err != nil {
+				continue
+			}
+			go func() {
+				for req := range reqs {
+					if req.Type != "exec" {
+						req.Reply(false, nil)
+						continue
+					}
+					var payload struct{ Command string }
+					ssh.Unmarshal(req.Payload, &payload)
+					req.Reply(true, nil)
+					ch.Write([]byte(testCommand(payload.Command)))
+					ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+					ch.Close()
+				}
+			}()
+		case "direct-tcpip":
+			var payload struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if err := ssh.Unmarshal(nc.ExtraData(), &payload); err != nil {
+				nc.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.FormatUint(uint64(payload.Port), 10)))
+			if err != nil {
+				nc.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			ch, reqs, err := nc.Accept()
+			if err != nil {
+				target.Close()
+				continue
+			}
+			go ssh.DiscardRequests(reqs)
+			go func() { io.Copy(ch, target); ch.Close() }()
+			go func() { io.Copy(target, ch); target.Close() }()
+		default:
+			nc.Reject(ssh.UnknownChannelType, "unsupported")
+		}
+	}
+}
+
+// knownHostsFile 写入known_hosts文件
+func knownHostsFile(t *testing.T, servers ...*testServer) string {
+	t.Helper()
+	var content string
+	for _, s := range servers {
+		content += knownhosts.Line([]string{knownhosts.Normalize(s.addr)}, s.hostKey.PublicKey()) + "\n"
+	}
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewSSHKnownHosts(t *testing.T) {
+	s := newTestServer(t, nil)
+	h, err := NewSSH(context.Background(), &Config{
+		Addr:       s.addr,
+		Username:   "root",
+		Password:   "secret",
+		KnownHosts: knownHostsFile(t, s),
+	})
+	if err != nil {
+		t.Fatalf("NewSSH: %s", err)
+	}
+	defer h.Close()
+	if got, ok := h.GetHost("peer0.org1.example.com"); !ok || got.Port() != "7051" || got.IP() != "127.0.0.1" {
+		t.Fatalf("GetHost: got %q %v", got, ok)
+	}
+	if _, ok := h.GetHost("couchdb0"); ok {
+		t.Fatal("GetHost: couchdb0 should not be discovered")
+	}
+	if id, ok := h.GetMspId("example.com"); !ok || id != "OrdererMSP" {
+		t.Fatalf("GetMspId: got %q %v", id, ok)
+	}
+}
+
+func TestNewSSHHostKeyMismatch(t *testing.T) {
+	s := newTestServer(t, nil)
+	other := newTestServer(t, nil)
+	other.addr = s.addr
+	_, err := NewSSH(context.Background(), &Config{
+		Addr:       s.addr,
+		Password:   "secret",
+		KnownHosts: knownHostsFile(t, other),
+	})
+	if err == nil {
+		t.Fatal("expected host key mismatch error")
+	}
+}
+
+// 远程模式下的错误不能回退为本机或者默认值
+func TestNewSftpError(t *testing.T) {
+	s := newTestServer(t, nil)
+	other := newTestServer(t, nil)
+	other.addr = s.addr
+	h, err := New(context.Background(), "sftp", &Config{
+		Addr:       s.addr,
+		Password:   "secret",
+		KnownHosts: knownHostsFile(t, other),
+	})
+	if err == nil || h != nil {
+		t.Fatalf("New: got %v %v, expected host key mismatch error", h, err)
+	}
+}
+
+func TestNewSSHFingerprint(t *testing.T) {
+	s := newTestServer(t, nil)
+	h, err := NewSSH(context.Background(), &Config{
+		Addr:        s.addr,
+		Password:    "secret",
+		Fingerprint: ssh.FingerprintSHA256(s.hostKey.PublicKey()),
+	})
+	if err != nil {
+		t.Fatalf("NewSSH: %s", err)
+	}
+	h.Close()
+
+	_, err = NewSSH(context.Background(), &Config{
+		Addr:        s.addr,
+		Password:    "secret",
+		Fingerprint: "SHA256:invalid",
+	})
+	if err == nil {
+		t.Fatal("expected fingerprint mismatch error")
+	}
+}
+
+func TestNewSSHEncryptedKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(key, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newTestServer(t, sshPub)
+	var prompted bool
+	h, err := NewSSH(context.Background(), &Config{
+		Addr:       s.addr,
+		PrivateKey: key,
+		KnownHosts: knownHostsFile(t, s),
+		Prompt: func(msg string) ([]byte, error) {
+			prompted = true
+			return []byte("passphrase"), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSSH: %s", err)
+	}
+	h.Close()
+	if !prompted {
+		t.Fatal("expected passphrase prompt")
+	}
+
+	_, err = NewSSH(context.Background(), &Config{
+		Addr:       s.addr,
+		PrivateKey: key,
+		KnownHosts: knownHostsFile(t, s),
+	})
+	if err == nil {
+		t.Fatal("expected error for encrypted key without passphrase")
+	}
+}
+
+func TestNewSSHJump(t *testing.T) {
+	bastion := newTestServer(t, nil)
+	target := newTestServer(t, nil)
+	h, err := NewSSH(context.Background(), &Config{
+		Addr:       target.addr,
+		Password:   "secret",
+		Jump:       []string{"root@" + bastion.addr},
+		KnownHosts: knownHostsFile(t, bastion, target),
+	})
+	if err != nil {
+		t.Fatalf("NewSSH: %s", err)
+	}
+	defer h.Close()
+	if len(h.jumps) != 1 {
+		t.Fatalf("jumps: got %d", len(h.jumps))
+	}
+	if _, ok := h.GetHost("orderer.example.com"); !ok {
+		t.Fatal("GetHost: orderer.example.com not found")
+	}
+}
+
+func TestNewSSHContextCanceled(t *testing.T) {
+	// 只接受连接不进行握手的服务
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	start := time.Now()
+	_, err = NewSSH(ctx, &Config{
+		Addr:     l.Addr().String(),
+		Password: "secret",
+		Insecure: true,
+	})
+	if err == nil {
+		t.Fatal("expected context error")
+	}
+	if time.Since(start) > time.Second*5 {
+		t.Fatalf("context cancel not honored: %s", time.Since(start))
+	}
+}
@@ -7,6 +7,7 @@ var (
 		"org3.example.com":     "Org3MSP",
 		"org4.example.com":     "Org4MSP",
 		"org5.example.com":     "Org5MSP",
+		"example.com":          "OrdererMSP",
 		"orderer.example.com":  "OrdererMSP",
 		"orderer2.example.com": "Orderer2MSP",
 		"orderer3.example.com": "Orderer3MSP",
@@ -21,6 +22,18 @@ func NewDefault() (FetchMspId, error) {
 	return &defaultMsp{}, nil
 }
 
+type mapMsp map[string]string
+
+// NewMap 使用固定的组织名称到mspid映射,例如从configtx.yaml中读取
+func NewMap(m map[string]string) FetchMspId {
+	return mapMsp(m)
+}
+
+func (m mapMsp) GetMspId(org string) (id string, ok bool) {
+	id, ok = m[org]
+	return
+}
+
 func (d *defaultMsp) GetMspId(org string) (id string, ok bool) {
 	id, ok = defaultMspId[org]
 	return
@@ -18,3 +18,19 @@ func New(mode string) (FetchMspId, error) {
 	// }
 	return NewDefault()
 }
+
+type chain []FetchMspId
+
+// NewChain 按顺序依次查询,返回第一个查询到的结果
+func NewChain(list ...FetchMspId) FetchMspId {
+	return chain(list)
+}
+
+func (c chain) GetMspId(org string) (id string, ok bool) {
+	for _, f := range c {
+		if id, ok = f.GetMspId(org); ok {
+			return
+		}
+	}
+	return "", false
+}
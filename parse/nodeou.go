@@ -0,0 +1,74 @@
+package parse
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OUIdentifier msp/config.yaml中NodeOUs下的角色标识
+type OUIdentifier struct {
+	Certificate                  string `yaml:"Certificate"`
+	OrganizationalUnitIdentifier string `yaml:"OrganizationalUnitIdentifier"`
+}
+
+// NodeOUs msp/config.yaml中的NodeOUs配置
+type NodeOUs struct {
+	Enable              bool         `yaml:"Enable"`
+	ClientOUIdentifier  OUIdentifier `yaml:"ClientOUIdentifier"`
+	PeerOUIdentifier    OUIdentifier `yaml:"PeerOUIdentifier"`
+	AdminOUIdentifier   OUIdentifier `yaml:"AdminOUIdentifier"`
+	OrdererOUIdentifier OUIdentifier `yaml:"OrdererOUIdentifier"`
+}
+
+// NodeOUs 读取msp/config.yaml,文件不存在时返回nil
+func (m Msp) NodeOUs() (*NodeOUs, error) {
+	if m.ConfigYaml.Yaml == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(string(m.ConfigYaml.Yaml))
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile:%w", err)
+	}
+	var cfg struct {
+		NodeOUs *NodeOUs `yaml:"NodeOUs"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("Unmarshal:%w", err)
+	}
+	return cfg.NodeOUs, nil
+}
+
+// Role 根据NodeOUs以及签名证书中的OU判断身份角色 peer orderer admin client,
+// 未开启NodeOUs或者无法判断时返回空
+func (m Msp) Role() (string, error) {
+	ous, err := m.NodeOUs()
+	if err != nil || ous == nil || !ous.Enable || m.SignCerts.Cert == "" {
+		return "", err
+	}
+	cert, err := m.SignCerts.Cert.Certificate()
+	if err != nil {
+		return "", fmt.Errorf("Certificate:%w", err)
+	}
+	roles := []struct {
+		name string
+		ou   string
+	}{
+		{"peer", ous.PeerOUIdentifier.OrganizationalUnitIdentifier},
+		{"orderer", ous.OrdererOUIdentifier.OrganizationalUnitIdentifier},
+		{"admin", ous.AdminOUIdentifier.OrganizationalUnitIdentifier},
+		{"client", ous.ClientOUIdentifier.OrganizationalUnitIdentifier},
+	}
+	for _, r := range roles {
+		if r.ou == "" {
+			continue
+		}
+		for _, ou := range cert.Subject.OrganizationalUnit {
+			if ou == r.ou {
+				return r.name, nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -1,10 +1,13 @@
 package parse
 
 import (
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -60,6 +63,23 @@ func (f File) Open() (string, error) {
 	return string(data), nil
 }
 
+// Certificate 解析pem格式证书
+func (f File) Certificate() (*x509.Certificate, error) {
+	data, err := os.ReadFile(string(f))
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile:%w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not pem", f)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("ParseCertificate:%w", err)
+	}
+	return cert, nil
+}
+
 type Package struct {
 	parentDir  string
 	currentDir string
@@ -98,6 +118,16 @@ type Org struct {
 	Users  map[UserDomain]*User
 }
 
+// Servers 获取排序后的节点域名列表
+func (o *Org) Servers() []string {
+	var list = make([]string, 0, len(o.Server))
+	for domain := range o.Server {
+		list = append(list, string(domain))
+	}
+	sort.Strings(list)
+	return list
+}
+
 type CryptoConfig struct {
 	Orgs  map[OrgName]*Org
 	Order map[OrgName]*Org
@@ -117,21 +147,23 @@ func (c *CryptoConfig) Valid() error {
 	return nil
 }
 
-// GetOrgName 获取peer组织名称列表
+// GetOrgName 获取排序后的peer组织名称列表
 func (c *CryptoConfig) GetOrgName() []string {
 	var list = make([]string, 0, len(c.Orgs))
 	for name := range c.Orgs {
 		list = append(list, string(name))
 	}
+	sort.Strings(list)
 	return list
 }
 
-// GetOrderName 获取order组织名称列表
+// GetOrderName 获取排序后的order组织名称列表
 func (c *CryptoConfig) GetOrderName() []string {
 	var list = make([]string, 0, len(c.Order))
 	for name := range c.Order {
 		list = append(list, string(name))
 	}
+	sort.Strings(list)
 	return list
 }
 